@@ -0,0 +1,267 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// backupFormatVersion is written to the manifest and bumped whenever the
+// layout of the archive changes, so restore can refuse archives it doesn't
+// understand.
+const backupFormatVersion = 1
+
+type backupManifest struct {
+	FormatVersion int           `json:"format_version"`
+	Created       string        `json:"created"`
+	Endpoint      string        `json:"endpoint"`
+	Tables        []backupTable `json:"tables"`
+}
+
+type backupTable struct {
+	Table  string `json:"table"`
+	File   string `json:"file"`
+	Count  int    `json:"count"`
+	SHA256 string `json:"sha256"`
+}
+
+// dogSnapshot holds every table as the API returns it.
+type dogSnapshot struct {
+	Profiles api.ProfilesList
+	Services api.ServicesList
+	Zones    api.ZonesList
+	Rulesets api.RulesetsList
+	Groups   api.GroupsListJson
+	Hosts    api.HostsListJson
+	Links    api.LinksList
+	Facts    api.FactsListJson
+}
+
+type snapshotTable struct {
+	table string
+	rows  any
+	count int
+}
+
+// tables returns the snapshot's tables in dependency order, which is the
+// order restore applies them in.
+func (s *dogSnapshot) tables() []snapshotTable {
+	return []snapshotTable{
+		{"profile", &s.Profiles, len(s.Profiles)},
+		{"service", &s.Services, len(s.Services)},
+		{"zone", &s.Zones, len(s.Zones)},
+		{"ruleset", &s.Rulesets, len(s.Rulesets)},
+		{"group", &s.Groups, len(s.Groups)},
+		{"host", &s.Hosts, len(s.Hosts)},
+		{"link", &s.Links, len(s.Links)},
+		{"fact", &s.Facts, len(s.Facts)},
+	}
+}
+
+func apiStatus(table string, statusCode int, err error, ok ...int) error {
+	if err != nil {
+		return fmt.Errorf("%s: %w", table, err)
+	}
+	for _, code := range ok {
+		if statusCode == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: status code %d", table, statusCode)
+}
+
+// fetchSnapshot lists every table. Rulesets are read with IDs rather than
+// names, so that restore can remap them.
+func fetchSnapshot(c *api.Client) (*dogSnapshot, error) {
+	s := &dogSnapshot{}
+	var statusCode int
+	var err error
+	s.Profiles, statusCode, err = c.GetProfiles(&api.ProfilesListOptions{Active: true})
+	if err := apiStatus("profile", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Services, statusCode, err = c.GetServices(nil)
+	if err := apiStatus("service", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Zones, statusCode, err = c.GetZones(nil)
+	if err := apiStatus("zone", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Rulesets, statusCode, err = c.GetRulesets(&api.RulesetsListOptions{Active: true})
+	if err := apiStatus("ruleset", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Groups, statusCode, err = c.GetGroups(nil)
+	if err := apiStatus("group", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Hosts, statusCode, err = c.GetHosts(&api.HostsListOptions{Active: "true"})
+	if err := apiStatus("host", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Links, statusCode, err = c.GetLinks(nil)
+	if err := apiStatus("link", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	s.Facts, statusCode, err = c.GetFacts(nil)
+	if err := apiStatus("fact", statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func runBackup(args []string) int {
+	fs := flag.NewFlagSet("dog-import backup", flag.ExitOnError)
+	file := fs.String("file", fmt.Sprintf("dog-backup-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z")), "archive to write")
+	profileFlag(fs)
+	fs.Parse(args)
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	snapshot, err := fetchSnapshot(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading dog: %s\n", err)
+		return 1
+	}
+	if err := writeBackup(*file, c.BaseURL, snapshot); err != nil {
+		fmt.Fprintf(os.Stderr, "writing %s: %s\n", *file, err)
+		return 1
+	}
+	for _, t := range snapshot.tables() {
+		fmt.Printf("%s: %d\n", t.table, t.count)
+	}
+	fmt.Printf("backup written to %s\n", *file)
+	return 0
+}
+
+// writeBackup writes a gzipped tar archive holding manifest.json and one
+// <table>.json file per table. Links include their passwords, so the archive
+// is only readable by the current user.
+func writeBackup(path string, endpoint string, snapshot *dogSnapshot) error {
+	manifest := backupManifest{
+		FormatVersion: backupFormatVersion,
+		Created:       time.Now().UTC().Format(time.RFC3339),
+		Endpoint:      endpoint,
+	}
+	files := map[string][]byte{}
+	for _, t := range snapshot.tables() {
+		data, err := json.MarshalIndent(t.rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		file := t.table + ".json"
+		files[file] = data
+		manifest.Tables = append(manifest.Tables, backupTable{Table: t.table, File: file, Count: t.count, SHA256: hex.EncodeToString(sum[:])})
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write("manifest.json", manifestData); err != nil {
+		return err
+	}
+	for _, t := range manifest.Tables {
+		if err := write(t.File, files[t.File]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b.Bytes(), 0600)
+}
+
+// readBackup reads an archive written by writeBackup, checking its format
+// version and the checksum of every table.
+func readBackup(path string) (*backupManifest, *dogSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[header.Name] = data
+	}
+
+	manifest := &backupManifest{}
+	data, ok := files["manifest.json"]
+	if !ok {
+		return nil, nil, fmt.Errorf("manifest.json missing, not a dog-import backup")
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, fmt.Errorf("manifest.json: %w", err)
+	}
+	if manifest.FormatVersion != backupFormatVersion {
+		return nil, nil, fmt.Errorf("unsupported backup format_version %d, expected %d", manifest.FormatVersion, backupFormatVersion)
+	}
+
+	snapshot := &dogSnapshot{}
+	rows := map[string]any{}
+	for _, t := range snapshot.tables() {
+		rows[t.table] = t.rows
+	}
+	for _, t := range manifest.Tables {
+		data, ok := files[t.File]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s missing from archive", t.File)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != t.SHA256 {
+			return nil, nil, fmt.Errorf("%s: checksum mismatch", t.File)
+		}
+		target, ok := rows[t.Table]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown table %s in manifest", t.Table)
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", t.File, err)
+		}
+	}
+	return manifest, snapshot, nil
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// environmentClients holds the API client of every environment given to
+// -environments.
+var environmentClients = map[string]*api.Client{}
+
+// tableReferences lists the tables whose names an exported table refers to.
+var tableReferences = map[string][]string{
+	"host":    {"group"},
+	"group":   {"profile"},
+	"ruleset": {"profile", "zone", "group", "service"},
+}
+
+var nonAlphanumericRe = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// environmentVariable returns the name of the per-environment variant of an
+// environment variable, such as DOG_API_TOKEN_QA for qa.
+func environmentVariable(name string, env string) string {
+	return name + "_" + strings.ToUpper(nonAlphanumericRe.ReplaceAllString(env, "_"))
+}
+
+// parseEnvironments validates -environments and reads the endpoint and token
+// of every environment, exiting with status 2 on bad input like flag.Parse
+// does.
+func parseEnvironments() {
+	for _, env := range strings.Split(environments, ",") {
+		env = strings.TrimSpace(env)
+		if env == "" {
+			continue
+		}
+		if slices.Contains(environment_list, env) {
+			fmt.Fprintf(os.Stderr, "environment '%s' is given twice in -environments\n", env)
+			os.Exit(2)
+		}
+		environment_list = append(environment_list, env)
+	}
+	if len(environment_list) == 0 {
+		fmt.Fprintf(os.Stderr, "-environments must list at least one environment\n")
+		os.Exit(2)
+	}
+	if module_address == "" || module_address == "root" {
+		fmt.Fprintf(os.Stderr, "-environments needs a -module address, the module is instantiated once per environment\n")
+		os.Exit(2)
+	}
+	if secret_vars != "" {
+		fmt.Fprintf(os.Stderr, "-secret_vars can't be used with -environments\n")
+		os.Exit(2)
+	}
+	for _, env := range environment_list {
+		endpoint := os.Getenv(environmentVariable("DOG_API_ENDPOINT", env))
+		token, err := apiToken(env)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "reading the dog API token for environment '%s': %s\n", env, err)
+			os.Exit(2)
+		}
+		if endpoint == "" || token == "" {
+			fmt.Fprintf(os.Stderr, "missing %s or %s for environment '%s'\n", environmentVariable("DOG_API_ENDPOINT", env), environmentVariable("DOG_API_TOKEN", env), env)
+			os.Exit(2)
+		}
+		environmentClients[env] = api.NewClient(token, endpoint)
+	}
+	// the module is given its provider by each caller
+	provider_address = ""
+}
+
+// consolidate exports every environment and writes one module holding them
+// all. Objects that are the same everywhere are written once; objects that
+// differ, or only exist in some environments, get a for_each over a local
+// holding the values of each environment, selected by var.environment.
+func consolidate(envs []string) []error {
+	errs := []error{}
+	contexts := map[string]*exportContext{}
+	for _, env := range envs {
+		contexts[env] = newExportContext(environmentClients[env], env, "")
+	}
+	if err := unifyNames(envs, contexts); err != nil {
+		return append(errs, err)
+	}
+
+	outputs := map[string]map[string]tableOutput{}
+	failed := map[string]bool{}
+	for _, env := range envs {
+		envOutputs, envErrs := runExporters(contexts[env], export_tables, parallelism)
+		for _, err := range envErrs {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+		}
+		outputs[env] = map[string]tableOutput{}
+		for _, out := range envOutputs {
+			outputs[env][out.table] = out
+		}
+	}
+
+	merged := []tableOutput{}
+	report := []envReport{}
+	forEach := map[string]bool{}
+	for _, table := range export_tables {
+		perEnv := []tableOutput{}
+		for _, env := range envs {
+			out, ok := outputs[env][table]
+			if !ok {
+				failed[table] = true
+				break
+			}
+			perEnv = append(perEnv, out)
+		}
+		if failed[table] {
+			// writing a table without one of the environments would drop
+			// its objects from the module
+			continue
+		}
+		out, tableReport := mergeTable(table, envs, perEnv)
+		for _, r := range tableReport {
+			forEach[r.Address] = true
+		}
+		merged = append(merged, out)
+		report = append(report, tableReport...)
+	}
+
+	for i := range merged {
+		for j := range merged[i].resources {
+			attrs := merged[i].resources[j].Attrs
+			for k := range attrs {
+				attrs[k].value = rewriteReferences(attrs[k].value, forEach, false)
+			}
+		}
+		for k := range merged[i].locals {
+			merged[i].locals[k].value = rewriteReferences(merged[i].locals[k].value, forEach, true)
+		}
+		if err := writeTable(output_dir, merged[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(merged) > 0 {
+		if err := writeVariables(output_dir, "environment", []variableBlock{{Name: "environment", Description: "dog environment of this instance of the module, one of: " + strings.Join(envs, ", ")}}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := writeEnvironmentReport(output_dir, report); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// unifyNames gives objects with the same dog name the same Terraform name in
+// every environment, so that merged resources and references line up.
+func unifyNames(envs []string, contexts map[string]*exportContext) error {
+	tables := []string{}
+	for _, table := range all_tables {
+		needed := slices.Contains(export_tables, table)
+		for _, exported := range export_tables {
+			if slices.Contains(tableReferences[exported], table) {
+				needed = true
+			}
+		}
+		if needed {
+			tables = append(tables, table)
+		}
+	}
+	for _, table := range tables {
+		perEnv := map[string][]namedObject{}
+		union := []namedObject{}
+		seen := map[string]bool{}
+		for _, env := range envs {
+			objects, err := fetchNamedObjects(contexts[env].c, table)
+			if err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+			sort.SliceStable(objects, func(i, j int) bool { return objects[i].ID < objects[j].ID })
+			perEnv[env] = objects
+			for _, key := range occurrenceKeys(objects) {
+				if !seen[key.ID] {
+					seen[key.ID] = true
+					union = append(union, key)
+				}
+			}
+		}
+		names := newTerraformNames(union)
+		for _, env := range envs {
+			envNames := &terraformNames{byID: map[string]string{}, byName: map[string]string{}, dogNames: map[string]string{}}
+			for i, key := range occurrenceKeys(perEnv[env]) {
+				obj := perEnv[env][i]
+				envNames.byID[obj.ID] = names.byID[key.ID]
+				envNames.dogNames[obj.ID] = obj.Name
+				if _, ok := envNames.byName[obj.Name]; !ok {
+					envNames.byName[obj.Name] = names.byID[key.ID]
+				}
+			}
+			contexts[env].setNames(table, envNames)
+		}
+	}
+	return nil
+}
+
+// occurrenceKeys identifies objects by name across environments, numbering
+// objects that share a name within one environment.
+func occurrenceKeys(objects []namedObject) []namedObject {
+	count := map[string]int{}
+	keys := make([]namedObject, len(objects))
+	for i, obj := range objects {
+		keys[i] = namedObject{ID: fmt.Sprintf("%s\x00%d", obj.Name, count[obj.Name]), Name: obj.Name}
+		count[obj.Name]++
+	}
+	return keys
+}
+
+// envReport describes an object that isn't the same in every environment.
+type envReport struct {
+	Table        string
+	Name         string
+	Address      string
+	Environments []string
+	Differing    []string
+}
+
+// mergeTable merges the output of one table from every environment.
+func mergeTable(table string, envs []string, perEnv []tableOutput) (tableOutput, []envReport) {
+	merged := tableOutput{table: table}
+	names := []string{}
+	byName := map[string]map[string]resourceBlock{}
+	for i, out := range perEnv {
+		for _, res := range out.resources {
+			if _, ok := byName[res.Name]; !ok {
+				names = append(names, res.Name)
+				byName[res.Name] = map[string]resourceBlock{}
+			}
+			byName[res.Name][envs[i]] = res
+		}
+	}
+
+	report := []envReport{}
+	for _, name := range names {
+		present := []string{}
+		for _, env := range envs {
+			if _, ok := byName[name][env]; ok {
+				present = append(present, env)
+			}
+		}
+		attrNames := []string{}
+		values := map[string]map[string]any{}
+		for _, env := range present {
+			for _, attr := range byName[name][env].Attrs {
+				if _, ok := values[attr.name]; !ok {
+					attrNames = append(attrNames, attr.name)
+					values[attr.name] = map[string]any{}
+				}
+				values[attr.name][env] = attr.value
+			}
+		}
+		differing := []string{}
+		for _, attr := range attrNames {
+			first := hclValue(values[attr][present[0]], 0)
+			for _, env := range present[1:] {
+				if hclValue(values[attr][env], 0) != first {
+					differing = append(differing, attr)
+					break
+				}
+			}
+		}
+		resourceType := byName[name][present[0]].Type
+		if len(present) == len(envs) && len(differing) == 0 {
+			merged.resources = append(merged.resources, byName[name][present[0]])
+			continue
+		}
+
+		local := fmt.Sprintf("%s_%s", strings.TrimPrefix(resourceType, "dog_"), name)
+		perEnvValues := object{}
+		for _, env := range present {
+			envValues := object{}
+			for _, attr := range differing {
+				envValues = append(envValues, attribute{attr, values[attr][env]})
+			}
+			perEnvValues = append(perEnvValues, attribute{env, envValues})
+		}
+		merged.locals = append(merged.locals, attribute{local, perEnvValues})
+		attrs := object{{"for_each", expr(fmt.Sprintf("{ for env, values in local.%s : env => values if env == var.environment }", local))}}
+		for _, attr := range attrNames {
+			if slices.Contains(differing, attr) {
+				attrs = append(attrs, attribute{attr, expr("each.value." + attr)})
+			} else {
+				attrs = append(attrs, attribute{attr, values[attr][present[0]]})
+			}
+		}
+		merged.resources = append(merged.resources, resourceBlock{Type: resourceType, Name: name, Attrs: attrs})
+		report = append(report, envReport{Table: table, Name: name, Address: resourceType + "." + name, Environments: present, Differing: differing})
+	}
+
+	forEach := map[string]bool{}
+	for _, r := range report {
+		forEach[r.Address] = true
+	}
+	seenVars := map[string]bool{}
+	for i, out := range perEnv {
+		module := fmt.Sprintf("%s_%s", module_address, envs[i])
+		for _, imp := range out.imports {
+			to := module + "." + imp.To
+			if forEach[imp.To] {
+				to += fmt.Sprintf("[%s]", hclString(envs[i]))
+			}
+			merged.imports = append(merged.imports, importBlock{ID: imp.ID, To: to})
+		}
+		for _, v := range out.variables {
+			if !seenVars[v.Name] {
+				seenVars[v.Name] = true
+				merged.variables = append(merged.variables, v)
+			}
+		}
+	}
+	return merged, report
+}
+
+var referenceRe = regexp.MustCompile(`^(dog_\w+\.[A-Za-z0-9_-]+)\.(\w+)$`)
+
+// rewriteReferences points references to resources that have a for_each at
+// the instance of the current environment. Locals hold the values of every
+// environment but are evaluated in only one, where the instances of the other
+// environments don't exist, so they use the instance that does.
+func rewriteReferences(v any, forEach map[string]bool, inLocal bool) any {
+	switch val := v.(type) {
+	case expr:
+		m := referenceRe.FindStringSubmatch(string(val))
+		if m == nil || !forEach[m[1]] {
+			return val
+		}
+		if inLocal {
+			return expr(fmt.Sprintf("one(values(%s)[*].%s)", m[1], m[2]))
+		}
+		return expr(fmt.Sprintf("%s[var.environment].%s", m[1], m[2]))
+	case []any:
+		list := make([]any, len(val))
+		for i, item := range val {
+			list[i] = rewriteReferences(item, forEach, inLocal)
+		}
+		return list
+	case object:
+		obj := make(object, len(val))
+		for i, attr := range val {
+			obj[i] = attribute{attr.name, rewriteReferences(attr.value, forEach, inLocal)}
+		}
+		return obj
+	default:
+		return v
+	}
+}
+
+// writeEnvironmentReport prints the objects that aren't the same in every
+// environment and lists them in environments.csv.
+func writeEnvironmentReport(output_dir string, report []envReport) error {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"table", "name", "address", "environments", "differing"})
+	for _, r := range report {
+		w.Write([]string{r.Table, r.Name, r.Address, strings.Join(r.Environments, " "), strings.Join(r.Differing, " ")})
+		if len(r.Environments) < len(environment_list) {
+			fmt.Printf("%s only in %s\n", r.Address, strings.Join(r.Environments, ", "))
+		}
+		if len(r.Differing) > 0 {
+			fmt.Printf("%s differs in %s\n", r.Address, strings.Join(r.Differing, ", "))
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return writeFileAtomic(fmt.Sprintf("%s/environments.csv", output_dir), b.Bytes(), 0644)
+}
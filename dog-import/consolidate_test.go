@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// prodZones are the zones of testdata/api with a different address for the
+// office zone.
+const prodZones = `[
+  {"id": "z1", "name": "office", "ipv4_addresses": ["10.1.0.0/16"], "ipv6_addresses": []},
+  {"id": "z2", "name": "1st zone", "ipv4_addresses": [""], "ipv6_addresses": []}
+]`
+
+func TestConsolidateGolden(t *testing.T) {
+	qa := fakeAPI(t)
+	prod := fakeAPIWith(t, map[string]string{"zones": prodZones})
+	t.Cleanup(func() {
+		environment_list = nil
+		environmentClients = map[string]*api.Client{}
+		mappings = nil
+	})
+	format = "hcl"
+	provider_address = ""
+	module_address = "module.dog"
+	export_tables = all_tables
+	include_re, exclude_re = nil, nil
+	plaintext_secrets = false
+	parallelism = 4
+	output_dir = t.TempDir()
+	environment_list = []string{"qa", "prod"}
+	environmentClients = map[string]*api.Client{
+		"qa":   api.NewClient("token", qa.URL),
+		"prod": api.NewClient("token", prod.URL),
+	}
+	mappings = nil
+
+	for _, err := range consolidate(environment_list) {
+		t.Errorf("consolidate: %s", err)
+	}
+	if err := writeNameMappings(output_dir); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, output_dir, filepath.Join("testdata", "golden", "environments"))
+
+	// only the address of the office zone differs
+	report, err := os.ReadFile(filepath.Join(output_dir, "environments.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "table,name,address,environments,differing\n" +
+		"zone,office,dog_zone.office,qa prod,ipv4_addresses\n"
+	if string(report) != want {
+		t.Errorf("environments.csv: got\n%s\nwant\n%s", report, want)
+	}
+}
@@ -0,0 +1,730 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Exit codes of the diff command, matching terraform plan -detailed-exitcode.
+const (
+	diffNoChanges = 0
+	diffError     = 1
+	diffChanges   = 2
+)
+
+// unknownValue stands in for config values that can't be known without
+// running Terraform, such as variables. They are never reported as drift.
+type unknownValue struct{}
+
+// dogObject is one dog object in the provider's attribute layout, either read
+// from Terraform or from the live API.
+type dogObject struct {
+	Table   string
+	Address string
+	ID      string
+	Name    string
+	Attrs   map[string]any
+}
+
+var diff_state string
+var diff_config_dir string
+
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("dog-import diff", flag.ExitOnError)
+	fs.StringVar(&diff_state, "state", "", "JSON state from 'terraform show -json'")
+	fs.StringVar(&diff_config_dir, "config_dir", "", "directory of .tf/.tf.json files containing dog_* resources")
+	filterFlags(fs)
+	profileFlag(fs)
+	fs.Parse(args)
+	if (diff_state == "") == (diff_config_dir == "") {
+		fmt.Fprintf(os.Stderr, "exactly one of -state or -config_dir is required\n")
+		return diffError
+	}
+	parseFilterFlags()
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return diffError
+	}
+	snapshot, err := fetchSnapshot(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading dog: %s\n", err)
+		return diffError
+	}
+	live, err := liveObjects(snapshot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading dog: %s\n", err)
+		return diffError
+	}
+
+	var terraform []dogObject
+	if diff_state != "" {
+		terraform, err = stateObjects(diff_state)
+	} else {
+		terraform, err = configObjects(diff_config_dir, live)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading terraform: %s\n", err)
+		return diffError
+	}
+
+	if printDiff(os.Stdout, terraform, live) {
+		return diffChanges
+	}
+	return diffNoChanges
+}
+
+// printDiff reports objects only in dog, only in Terraform, and attributes
+// that differ to w. It returns true when any drift was found.
+func printDiff(w io.Writer, terraform []dogObject, live []dogObject) bool {
+	liveByID := map[string]*dogObject{}
+	liveByName := map[string]*dogObject{}
+	for i := range live {
+		obj := &live[i]
+		liveByID[obj.Table+"/"+obj.ID] = obj
+		if _, ok := liveByName[obj.Table+"/"+obj.Name]; !ok {
+			liveByName[obj.Table+"/"+obj.Name] = obj
+		}
+	}
+
+	drift := false
+	matched := map[*dogObject]bool{}
+	for _, tf := range terraform {
+		if !slices.Contains(export_tables, tf.Table) || !selected(tf.Name) {
+			continue
+		}
+		obj, ok := liveByID[tf.Table+"/"+tf.ID]
+		if !ok && tf.ID == "" {
+			obj, ok = liveByName[tf.Table+"/"+tf.Name]
+		}
+		if !ok {
+			drift = true
+			fmt.Fprintf(w, "only in terraform: %s (%s)\n", tf.Address, tf.Name)
+			continue
+		}
+		matched[obj] = true
+		changes := compareAttributes(tf.Table, tf.Attrs, obj.Attrs)
+		if len(changes) > 0 {
+			drift = true
+			fmt.Fprintf(w, "changed: %s (%s)\n", tf.Address, obj.ID)
+			for _, change := range changes {
+				fmt.Fprintf(w, "    %s\n", change)
+			}
+		}
+	}
+	for i := range live {
+		obj := &live[i]
+		if matched[obj] || !slices.Contains(export_tables, obj.Table) || !selected(obj.Name) {
+			continue
+		}
+		drift = true
+		fmt.Fprintf(w, "only in dog: dog_%s %q (%s)\n", obj.Table, obj.Name, obj.ID)
+	}
+	if !drift {
+		fmt.Fprintf(w, "no differences\n")
+	}
+	return drift
+}
+
+// providerOnlyAttributes lists the attributes of each table that only exist
+// in the provider, by flattened path. dog never has them, so they are not
+// compared. A link whose password is given with password_wo has a null
+// password in the state, which is not compared either.
+var providerOnlyAttributes = map[string][]string{
+	"fact":    {"deletion_protection"},
+	"group":   {"deletion_protection", "force_delete"},
+	"link":    {"deletion_protection", "dog_connection.password_wo", "dog_connection.password_wo_version"},
+	"profile": {"deletion_protection", "force_delete"},
+	"ruleset": {"deletion_protection"},
+	"service": {"deletion_protection", "force_delete"},
+	"zone":    {"deletion_protection", "force_delete"},
+}
+
+// compareAttributes compares every attribute of a table set in Terraform with
+// dog. Attributes Terraform leaves null, or can't know, are not managed and
+// are skipped, as are the provider only attributes.
+func compareAttributes(table string, terraform map[string]any, live map[string]any) []string {
+	if table == "ruleset" {
+		terraform = rulesFromKeyedRules(terraform)
+	}
+	tfFlat := map[string]string{}
+	liveFlat := map[string]string{}
+	for key, value := range terraform {
+		if key == "id" {
+			continue
+		}
+		flatten(tfFlat, key, value)
+		flatten(liveFlat, key, live[key])
+	}
+	keys := []string{}
+	for key := range tfFlat {
+		if !providerOnly(table, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	changes := []string{}
+	for _, key := range keys {
+		liveValue, ok := liveFlat[key]
+		if !ok {
+			liveValue = "(null)"
+		}
+		if tfFlat[key] == liveValue {
+			continue
+		}
+		if strings.HasSuffix(key, "password") {
+			changes = append(changes, fmt.Sprintf("%s: (sensitive value differs)", key))
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: terraform %s, dog %s", key, tfFlat[key], liveValue))
+		}
+	}
+	return changes
+}
+
+// providerOnly reports whether a flattened path is, or is inside, one of the
+// provider only attributes of a table.
+func providerOnly(table string, key string) bool {
+	for _, attr := range providerOnlyAttributes[table] {
+		if key == attr || strings.HasPrefix(key, attr+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesFromKeyedRules returns the attributes of a dog_ruleset with its
+// keyed_rules converted to the rules lists the provider sends to dog: by
+// ascending priority, rules without one last, then by name.
+func rulesFromKeyedRules(attrs map[string]any) map[string]any {
+	keyed, ok := attrs["keyed_rules"].(map[string]any)
+	if !ok {
+		return attrs
+	}
+	converted := map[string]any{}
+	for key, value := range attrs {
+		if key != "keyed_rules" {
+			converted[key] = value
+		}
+	}
+	rules := map[string]any{}
+	for _, direction := range []string{"inbound", "outbound"} {
+		byName, ok := keyed[direction].(map[string]any)
+		if !ok {
+			rules[direction] = keyed[direction]
+			continue
+		}
+		names := []string{}
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			pi, iok := rulePriority(byName[names[i]])
+			pj, jok := rulePriority(byName[names[j]])
+			if iok != jok {
+				return iok
+			}
+			if pi != pj {
+				return pi < pj
+			}
+			return names[i] < names[j]
+		})
+		list := []any{}
+		for _, name := range names {
+			rule, ok := byName[name].(map[string]any)
+			if !ok {
+				list = append(list, byName[name])
+				continue
+			}
+			copied := map[string]any{}
+			for key, value := range rule {
+				if key != "priority" {
+					copied[key] = value
+				}
+			}
+			list = append(list, copied)
+		}
+		rules[direction] = list
+	}
+	converted["rules"] = rules
+	return converted
+}
+
+func rulePriority(rule any) (float64, bool) {
+	r, _ := rule.(map[string]any)
+	priority, ok := r["priority"].(float64)
+	return priority, ok
+}
+
+// flatten writes value into flat as path => canonical string. Lists record
+// their length, and the fact groups map its keys, so that extra entries in dog
+// are reported too.
+func flatten(flat map[string]string, path string, value any) {
+	switch val := value.(type) {
+	case nil, unknownValue:
+		return
+	case map[string]any:
+		keys := []string{}
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if path == "groups" {
+			flat[path+".%"] = strings.Join(keys, ",")
+		}
+		for _, key := range keys {
+			flatten(flat, path+"."+key, val[key])
+		}
+	case []any:
+		flat[path+".#"] = fmt.Sprint(len(val))
+		for i, item := range val {
+			flatten(flat, fmt.Sprintf("%s.%d", path, i), item)
+		}
+	case string:
+		flat[path] = canonicalString(val)
+		if flat[path] == "" {
+			flat[path] = `""`
+		}
+	case float64:
+		flat[path] = formatNumber(val)
+	case int:
+		flat[path] = fmt.Sprint(val)
+	case bool:
+		flat[path] = fmt.Sprint(val)
+	default:
+		flat[path] = fmt.Sprint(val)
+	}
+}
+
+// canonicalString re-encodes JSON documents such as vars so that key order
+// and whitespace don't show up as drift. Other strings are compared as is, the
+// way Terraform converts "1" and 1 into each other.
+func canonicalString(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var decoded any
+		if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+			encoded, _ := json.Marshal(decoded)
+			return string(encoded)
+		}
+	}
+	return s
+}
+
+// jsonConverter round-trips API structs through JSON into the generic form
+// the comparison works on, keeping the first error.
+type jsonConverter struct {
+	err error
+}
+
+func (j *jsonConverter) object(v any) map[string]any {
+	obj := map[string]any{}
+	encoded, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(encoded, &obj)
+	}
+	if j.err == nil {
+		j.err = err
+	}
+	return obj
+}
+
+func (j *jsonConverter) string(v any) any {
+	encoded, err := json.Marshal(v)
+	if j.err == nil {
+		j.err = err
+	}
+	return string(encoded)
+}
+
+// liveObjects converts every table of a snapshot into the provider's
+// attribute layout.
+func liveObjects(s *dogSnapshot) ([]dogObject, error) {
+	j := &jsonConverter{}
+	objects := []dogObject{}
+	add := func(table string, id string, name string, attrs map[string]any) {
+		attrs["id"] = id
+		objects = append(objects, dogObject{Table: table, Address: fmt.Sprintf("dog_%s", table), ID: id, Name: name, Attrs: attrs})
+	}
+	for _, row := range s.Groups {
+		if row.ID == "all-active" {
+			continue
+		}
+		profileVersion := row.ProfileVersion
+		if profileVersion == "" {
+			// exported groups follow the latest profile, as dog does
+			profileVersion = "latest"
+		}
+		attrs := map[string]any{
+			"description":            row.Description,
+			"name":                   row.Name,
+			"profile_id":             row.ProfileId,
+			"profile_name":           row.ProfileName,
+			"profile_version":        profileVersion,
+			"ec2_security_group_ids": j.object(map[string]any{"v": row.Ec2SecurityGroupIds})["v"],
+		}
+		if row.Vars != nil {
+			attrs["vars"] = j.string(row.Vars)
+		}
+		if row.AlertEnable != nil {
+			attrs["alert_enable"] = *row.AlertEnable
+		}
+		add("group", row.ID, row.Name, attrs)
+	}
+
+	for _, row := range s.Hosts {
+		attrs := map[string]any{
+			"environment": row.Environment,
+			"group":       row.Group,
+			"hostkey":     row.HostKey,
+			"location":    row.Location,
+			"name":        row.Name,
+		}
+		if row.Vars != nil {
+			attrs["vars"] = j.string(row.Vars)
+		}
+		if row.AlertEnable != nil {
+			attrs["alert_enable"] = *row.AlertEnable
+		}
+		add("host", row.ID, row.Name, attrs)
+	}
+
+	for _, row := range s.Links {
+		attrs := j.object(row)
+		attrs["dog_connection"] = attrs["connection"]
+		delete(attrs, "connection")
+		add("link", row.ID, row.Name, attrs)
+	}
+
+	for _, row := range s.Rulesets {
+		attrs := j.object(row)
+		if rules, ok := attrs["rules"].(map[string]any); ok {
+			for _, direction := range []string{"inbound", "outbound"} {
+				list, _ := rules[direction].([]any)
+				for _, rule := range list {
+					if r, ok := rule.(map[string]any); ok {
+						delete(r, "order")
+					}
+				}
+			}
+		}
+		add("ruleset", row.ID, row.Name, attrs)
+	}
+
+	for _, row := range s.Profiles {
+		add("profile", row.ID, row.Name, j.object(row))
+	}
+
+	for _, row := range s.Services {
+		attrs := j.object(row)
+		add("service", row.ID, row.Name, attrs)
+	}
+
+	for _, row := range s.Zones {
+		add("zone", row.ID, row.Name, map[string]any{
+			"name":           row.Name,
+			"ipv4_addresses": addresses(row.IPv4Addresses),
+			"ipv6_addresses": addresses(row.IPv6Addresses),
+		})
+	}
+
+	for _, row := range s.Facts {
+		groups := map[string]any{}
+		for name, group := range row.Groups {
+			factGroup := map[string]any{
+				"children": stringList(group.Children),
+			}
+			if group.Hosts != nil {
+				factGroup["hosts"] = j.string(group.Hosts)
+			}
+			if group.Vars != nil {
+				factGroup["vars"] = j.string(group.Vars)
+			}
+			groups[name] = factGroup
+		}
+		add("fact", row.ID, row.Name, map[string]any{
+			"name":   row.Name,
+			"groups": groups,
+		})
+	}
+	return objects, j.err
+}
+
+// tableForType returns the dog-import table of a dog_* resource type.
+func tableForType(resourceType string) (string, bool) {
+	table, ok := strings.CutPrefix(resourceType, "dog_")
+	return table, ok && slices.Contains(all_tables, table)
+}
+
+type terraformState struct {
+	Values struct {
+		RootModule stateModule `json:"root_module"`
+	} `json:"values"`
+}
+
+type stateModule struct {
+	Resources []struct {
+		Address string         `json:"address"`
+		Mode    string         `json:"mode"`
+		Type    string         `json:"type"`
+		Values  map[string]any `json:"values"`
+	} `json:"resources"`
+	ChildModules []stateModule `json:"child_modules"`
+}
+
+// stateObjects reads the managed dog_* resources of every module from the
+// output of terraform show -json.
+func stateObjects(path string) ([]dogObject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	objects := []dogObject{}
+	var walk func(module stateModule)
+	walk = func(module stateModule) {
+		for _, res := range module.Resources {
+			table, ok := tableForType(res.Type)
+			if !ok || res.Mode != "managed" {
+				continue
+			}
+			id, _ := res.Values["id"].(string)
+			name, _ := res.Values["name"].(string)
+			objects = append(objects, dogObject{Table: table, Address: res.Address, ID: id, Name: name, Attrs: res.Values})
+		}
+		for _, child := range module.ChildModules {
+			walk(child)
+		}
+	}
+	walk(state.Values.RootModule)
+	return objects, nil
+}
+
+type configResource struct {
+	Table   string
+	Address string
+	Attrs   hcl.Attributes
+}
+
+var metaArguments = map[string]bool{
+	"provider":   true,
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+	"lifecycle":  true,
+}
+
+// configObjects evaluates the dog_* resources in a directory. References to
+// other dog resources resolve to the matching live object, and the IDs in
+// import blocks are used to match resources with dog objects.
+func configObjects(dir string, live []dogObject) ([]dogObject, error) {
+	parser := hclparse.NewParser()
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	jsonFiles, err := filepath.Glob(filepath.Join(dir, "*.tf.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "resource", LabelNames: []string{"type", "name"}},
+			{Type: "import"},
+		},
+	}
+	resources := []configResource{}
+	importIDs := map[string]string{}
+	for _, file := range append(files, jsonFiles...) {
+		var f *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(file, ".json") {
+			f, diags = parser.ParseJSONFile(file)
+		} else {
+			f, diags = parser.ParseHCLFile(file)
+		}
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		content, _, _ := f.Body.PartialContent(schema)
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "resource":
+				table, ok := tableForType(block.Labels[0])
+				if !ok {
+					continue
+				}
+				attrs, _ := block.Body.JustAttributes()
+				resources = append(resources, configResource{
+					Table:   table,
+					Address: block.Labels[0] + "." + block.Labels[1],
+					Attrs:   attrs,
+				})
+			case "import":
+				attrs, _ := block.Body.JustAttributes()
+				if attrs["id"] == nil || attrs["to"] == nil {
+					continue
+				}
+				id, diags := attrs["id"].Expr.Value(nil)
+				traversal, tDiags := hcl.AbsTraversalForExpr(attrs["to"].Expr)
+				if diags.HasErrors() || tDiags.HasErrors() || id.Type() != cty.String {
+					continue
+				}
+				to := traversalString(traversal)
+				// import blocks written for a module address the resources
+				// inside it by their last two parts
+				parts := strings.Split(to, ".")
+				if len(parts) >= 2 {
+					importIDs[strings.Join(parts[len(parts)-2:], ".")] = id.AsString()
+				}
+			}
+		}
+	}
+
+	liveByName := map[string]dogObject{}
+	liveByID := map[string]dogObject{}
+	for _, obj := range live {
+		liveByID[obj.Table+"/"+obj.ID] = obj
+		if _, ok := liveByName[obj.Table+"/"+obj.Name]; !ok {
+			liveByName[obj.Table+"/"+obj.Name] = obj
+		}
+	}
+
+	// Resolve each resource to a live object first, so that references such
+	// as dog_zone.office.id evaluate to the ID dog knows the zone by.
+	objects := make([]dogObject, len(resources))
+	references := map[string]map[string]cty.Value{}
+	for i, res := range resources {
+		obj := dogObject{Table: res.Table, Address: res.Address, ID: importIDs[res.Address]}
+		if attr, ok := res.Attrs["name"]; ok {
+			if name, diags := attr.Expr.Value(nil); !diags.HasErrors() && name.Type() == cty.String && name.IsKnown() && !name.IsNull() {
+				obj.Name = name.AsString()
+			}
+		}
+		match, ok := liveByID[obj.Table+"/"+obj.ID]
+		if !ok && obj.ID == "" {
+			match, ok = liveByName[obj.Table+"/"+obj.Name]
+		}
+		id := cty.UnknownVal(cty.String)
+		if ok {
+			id = cty.StringVal(match.ID)
+		}
+		name := cty.UnknownVal(cty.String)
+		if obj.Name != "" {
+			name = cty.StringVal(obj.Name)
+		}
+		resourceType := "dog_" + res.Table
+		if references[resourceType] == nil {
+			references[resourceType] = map[string]cty.Value{}
+		}
+		tfName := strings.TrimPrefix(res.Address, resourceType+".")
+		references[resourceType][tfName] = cty.ObjectVal(map[string]cty.Value{"id": id, "name": name})
+		objects[i] = obj
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{},
+		Functions: map[string]function.Function{
+			"jsonencode": stdlib.JSONEncodeFunc,
+			"jsondecode": stdlib.JSONDecodeFunc,
+			"concat":     stdlib.ConcatFunc,
+			"merge":      stdlib.MergeFunc,
+			"lower":      stdlib.LowerFunc,
+			"upper":      stdlib.UpperFunc,
+			"format":     stdlib.FormatFunc,
+		},
+	}
+	for resourceType, byName := range references {
+		ctx.Variables[resourceType] = cty.ObjectVal(byName)
+	}
+
+	for i, res := range resources {
+		attrs := map[string]any{}
+		for name, attr := range res.Attrs {
+			if metaArguments[name] {
+				continue
+			}
+			// undefined variables and locals evaluate to unknown values,
+			// which are skipped when comparing
+			value, _ := attr.Expr.Value(ctx)
+			attrs[name] = ctyToGo(value)
+		}
+		objects[i].Attrs = attrs
+	}
+	return objects, nil
+}
+
+func traversalString(traversal hcl.Traversal) string {
+	parts := []string{}
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			parts = append(parts, s.Name)
+		case hcl.TraverseAttr:
+			parts = append(parts, s.Name)
+		case hcl.TraverseIndex:
+			parts[len(parts)-1] += fmt.Sprintf("[%s]", ctyToString(s.Key))
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func ctyToString(v cty.Value) string {
+	if v.Type() == cty.String {
+		return fmt.Sprintf("%q", v.AsString())
+	}
+	return v.AsBigFloat().String()
+}
+
+// ctyToGo converts an evaluated config value into the generic JSON form the
+// comparison works on.
+func ctyToGo(v cty.Value) any {
+	if !v.IsKnown() {
+		return unknownValue{}
+	}
+	if v.IsNull() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Number:
+		f, _ := v.AsBigFloat().Float64()
+		return f
+	case t == cty.Bool:
+		return v.True()
+	case t.IsListType() || t.IsTupleType() || t.IsSetType():
+		list := []any{}
+		for it := v.ElementIterator(); it.Next(); {
+			_, item := it.Element()
+			list = append(list, ctyToGo(item))
+		}
+		return list
+	case t.IsMapType() || t.IsObjectType():
+		obj := map[string]any{}
+		for it := v.ElementIterator(); it.Next(); {
+			key, item := it.Element()
+			obj[key.AsString()] = ctyToGo(item)
+		}
+		return obj
+	}
+	return unknownValue{}
+}
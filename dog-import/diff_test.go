@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// liveFromFakeAPI reads every table of the fake API into the layout the diff
+// compares.
+func liveFromFakeAPI(t *testing.T, endpoint string) []dogObject {
+	t.Helper()
+	snapshot, err := fetchSnapshot(api.NewClient("token", endpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := liveObjects(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return live
+}
+
+func TestDiffExportedConfig(t *testing.T) {
+	server := fakeAPI(t)
+	for _, outputFormat := range []string{"hcl", "json"} {
+		t.Run(outputFormat, func(t *testing.T) {
+			dir := exportFormat(t, server.URL, outputFormat)
+			live := liveFromFakeAPI(t, server.URL)
+			terraform, err := configObjects(dir, live)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if printDiff(&out, terraform, live) {
+				t.Errorf("the exported configuration drifts from dog:\n%s", out.String())
+			}
+			if out.String() != "no differences\n" {
+				t.Errorf("got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestDiffZones(t *testing.T) {
+	server := fakeAPI(t)
+	tests := []struct {
+		name  string
+		file  string
+		state bool
+		input string
+		want  string
+	}{
+		{
+			name: "config",
+			file: "zone.tf",
+			input: `
+resource "dog_zone" "office" {
+  name           = "office"
+  ipv4_addresses = ["10.0.0.0/24"]
+  ipv6_addresses = []
+}
+
+resource "dog_zone" "lab" {
+  name           = "lab"
+  ipv4_addresses = ["192.168.0.0/16"]
+  ipv6_addresses = var.lab_ipv6
+}
+`,
+			want: "changed: dog_zone.office (z1)\n" +
+				"    ipv4_addresses.0: terraform 10.0.0.0/24, dog 10.0.0.0/8\n" +
+				"only in terraform: dog_zone.lab (lab)\n" +
+				"only in dog: dog_zone \"1st zone\" (z2)\n",
+		},
+		{
+			name:  "state",
+			file:  "state.json",
+			state: true,
+			input: `{
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "dog_zone.office",
+          "mode": "managed",
+          "type": "dog_zone",
+          "values": {"id": "z1", "name": "office", "ipv4_addresses": ["10.0.0.0/24"], "ipv6_addresses": []}
+        },
+        {
+          "address": "data.dog_zone.office",
+          "mode": "data",
+          "type": "dog_zone",
+          "values": {"id": "z1", "name": "office", "ipv4_addresses": ["172.16.0.0/12"], "ipv6_addresses": []}
+        }
+      ],
+      "child_modules": [
+        {
+          "resources": [
+            {
+              "address": "module.dog.dog_zone.lab",
+              "mode": "managed",
+              "type": "dog_zone",
+              "values": {"id": "z9", "name": "lab", "ipv4_addresses": ["192.168.0.0/16"], "ipv6_addresses": []}
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`,
+			want: "changed: dog_zone.office (z1)\n" +
+				"    ipv4_addresses.0: terraform 10.0.0.0/24, dog 10.0.0.0/8\n" +
+				"only in terraform: module.dog.dog_zone.lab (lab)\n" +
+				"only in dog: dog_zone \"1st zone\" (z2)\n",
+		},
+		{
+			name:  "state with deletion_protection and force_delete",
+			file:  "state.json",
+			state: true,
+			input: `{
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "dog_zone.office",
+          "mode": "managed",
+          "type": "dog_zone",
+          "values": {"id": "z1", "name": "office", "ipv4_addresses": ["10.0.0.0/8"], "ipv6_addresses": [], "deletion_protection": true, "force_delete": true}
+        }
+      ]
+    }
+  }
+}
+`,
+			want: "only in dog: dog_zone \"1st zone\" (z2)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			export_tables = []string{"zone"}
+			include_re, exclude_re = nil, nil
+			dir := t.TempDir()
+			path := filepath.Join(dir, test.file)
+			if err := os.WriteFile(path, []byte(test.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			live := liveFromFakeAPI(t, server.URL)
+			var terraform []dogObject
+			var err error
+			if test.state {
+				terraform, err = stateObjects(path)
+			} else {
+				terraform, err = configObjects(dir, live)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if !printDiff(&out, terraform, live) {
+				t.Errorf("no drift reported")
+			}
+			if out.String() != test.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinkWriteOnlyPassword(t *testing.T) {
+	server := fakeAPI(t)
+	tests := []struct {
+		name  string
+		file  string
+		state bool
+		input string
+	}{
+		{
+			name: "config",
+			file: "link.tf",
+			input: `
+resource "dog_link" "q1" {
+  name = "q1"
+  dog_connection = {
+    host                = "broker"
+    port                = 5673
+    password_wo         = var.q1_password
+    password_wo_version = 2
+  }
+}
+`,
+		},
+		{
+			name:  "state",
+			file:  "state.json",
+			state: true,
+			input: `{
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "dog_link.q1",
+          "mode": "managed",
+          "type": "dog_link",
+          "values": {
+            "id": "l1",
+            "name": "q1",
+            "dog_connection": {"host": "broker", "port": 5673, "password": null, "password_wo": null, "password_wo_version": 2}
+          }
+        }
+      ]
+    }
+  }
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			export_tables = []string{"link"}
+			include_re, exclude_re = nil, nil
+			dir := t.TempDir()
+			path := filepath.Join(dir, test.file)
+			if err := os.WriteFile(path, []byte(test.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			live := liveFromFakeAPI(t, server.URL)
+			var terraform []dogObject
+			var err error
+			if test.state {
+				terraform, err = stateObjects(path)
+			} else {
+				terraform, err = configObjects(dir, live)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if printDiff(&out, terraform, live) {
+				t.Errorf("drift reported for a link using password_wo:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestDiffRulesetKeyedRules(t *testing.T) {
+	server := fakeAPI(t)
+	tests := []struct {
+		name  string
+		file  string
+		state bool
+		input string
+		want  string
+	}{
+		{
+			name:  "state",
+			file:  "state.json",
+			state: true,
+			input: `{
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "dog_ruleset.web",
+          "mode": "managed",
+          "type": "dog_ruleset",
+          "values": {
+            "id": "r1",
+            "name": "web",
+            "profile_id": "p1",
+            "rules": null,
+            "keyed_rules": {
+              "inbound": {
+                "drop_all": {"action": "DROP", "group": "any", "group_type": "ANY", "service": "any", "priority": null},
+                "web_prod": {"action": "ACCEPT", "group": "g2", "group_type": "ROLE", "service": "any", "priority": 2},
+                "ssh": {"action": "ACCEPT", "group": "z1", "group_type": "ZONE", "service": "s1", "priority": 1}
+              },
+              "outbound": {
+                "all": {"action": "ACCEPT", "group": "any", "group_type": "ANY", "service": "any", "priority": null}
+              }
+            }
+          }
+        }
+      ]
+    }
+  }
+}
+`,
+			want: "only in dog: dog_ruleset \"db-rules\" (r2)\n",
+		},
+		{
+			name: "config",
+			file: "ruleset.tf",
+			input: `
+resource "dog_ruleset" "web" {
+  name = "web"
+  keyed_rules = {
+    inbound = {
+      drop_all = { action = "DROP", group = "any", group_type = "ANY", service = "any" }
+      ssh      = { action = "ACCEPT", group = "z1", group_type = "ZONE", service = "s9", priority = 1 }
+      web_prod = { action = "ACCEPT", group = "g2", group_type = "ROLE", service = "any", priority = 2 }
+    }
+    outbound = {
+      all = { action = "ACCEPT", group = "any", group_type = "ANY", service = "any" }
+    }
+  }
+}
+`,
+			want: "changed: dog_ruleset.web (r1)\n" +
+				"    rules.inbound.0.service: terraform s9, dog s1\n" +
+				"only in dog: dog_ruleset \"db-rules\" (r2)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			export_tables = []string{"ruleset"}
+			include_re, exclude_re = nil, nil
+			dir := t.TempDir()
+			path := filepath.Join(dir, test.file)
+			if err := os.WriteFile(path, []byte(test.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			live := liveFromFakeAPI(t, server.URL)
+			var terraform []dogObject
+			var err error
+			if test.state {
+				terraform, err = stateObjects(path)
+			} else {
+				terraform, err = configObjects(dir, live)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			printDiff(&out, terraform, live)
+			if out.String() != test.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), test.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,12 @@ module dog-import
 
 go 1.21
 
-require github.com/relaypro-open/dog_api_golang v1.0.5-0.20240524210628-9379ab314091
+require (
+	github.com/hashicorp/hcl/v2 v2.19.1
+	github.com/relaypro-open/dog_api_golang v1.0.5-0.20240524210628-9379ab314091
+	github.com/zclconf/go-cty v1.14.2
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/agext/levenshtein v1.2.1 // indirect
@@ -12,13 +17,11 @@ require (
 	github.com/google/go-cmp v0.3.1 // indirect
 	github.com/gookit/color v1.5.4 // indirect
 	github.com/gookit/goutil v0.6.15 // indirect
-	github.com/hashicorp/hcl/v2 v2.19.1 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
 	github.com/rogpeppe/go-internal v1.9.0 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
-	github.com/zclconf/go-cty v1.14.2 // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
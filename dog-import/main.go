@@ -1,603 +1,780 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"reflect"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/relaypro-open/dog_api_golang/api"
 )
 
 func Pretty(incoming interface{}) (str string) {
-    d, _ := json.MarshalIndent(incoming, "", "  ")
-    if string(d) == "null" {
-        return ""
-    } else {
-        return string(d)
-    }
+	d, _ := json.MarshalIndent(incoming, "", "  ")
+	if string(d) == "null" {
+		return ""
+	} else {
+		return string(d)
+	}
 }
 
-func check(err error) {
-    if err != nil {
-        panic(err)
-    }
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so that a failed run never leaves a half written file behind.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
 
-func toTerraformName(name string) string {
-    no_dots := strings.ReplaceAll(name, ".", "_")
-    no_open_parenthesis := strings.ReplaceAll(no_dots, "(", "_")
-    no_close_parenthesis := strings.ReplaceAll(no_open_parenthesis, ")", "_")
-    no_forward_slash := strings.ReplaceAll(no_close_parenthesis, "/", "_")
-    no_spaces := strings.ReplaceAll(no_forward_slash, " ", "_")
-    no_colons := strings.ReplaceAll(no_spaces, ":", "_")
-    return no_colons
+// writeTable writes the resources and import blocks of a table as <table>.tf
+// and <table>_import.tf, or their .tf.json equivalents, and the variables it
+// needs as <table>_variables.tf.
+func writeTable(output_dir string, out tableOutput) error {
+	var tf, imp []byte
+	extension := "tf"
+	if format == "json" {
+		extension = "tf.json"
+		var err error
+		if tf, err = renderJSON(out.locals, out.resources); err != nil {
+			return fmt.Errorf("%s: %w", out.table, err)
+		}
+		if imp, err = renderJSONImports(out.imports); err != nil {
+			return fmt.Errorf("%s: %w", out.table, err)
+		}
+	} else {
+		tf = renderHCL(out.locals, out.resources)
+		imp = renderHCLImports(out.imports)
+	}
+	if err := writeFileAtomic(fmt.Sprintf("%s/%s.%s", output_dir, out.table, extension), tf, 0644); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(fmt.Sprintf("%s/%s_import.%s", output_dir, out.table, extension), imp, 0644); err != nil {
+		return err
+	}
+	return writeVariables(output_dir, out.table, out.variables)
 }
 
-func createDir(output_dir string, table string) {
-    if err := os.MkdirAll(fmt.Sprintf("%s/%s", output_dir, table), os.ModePerm); err != nil {
-        log.Fatal(err)
-    }
-}
+func link_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("link_export\n")
+	table := "link"
 
-func terraformOutputFile(output_dir string, table string) *bufio.Writer {
-    tf_f, err := os.Create(fmt.Sprintf("%s/%s.tf", output_dir, table))
-    check(err)
-    tf_w := bufio.NewWriter(tf_f)
+	res, statusCode, err := x.c.GetLinks(nil)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
 
-    return tf_w
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	vars := []variableBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		connection := object{}
+		if row.Connection != nil {
+			sslOptions := object{}
+			if row.Connection.SSLOptions != nil {
+				sslOptions = object{
+					{"cacertfile", secret(&vars, table, terraformName, "cacertfile", fmt.Sprintf("CA certificate file of dog link %s", row.Name), row.Connection.SSLOptions.CaCertFile)},
+					{"certfile", secret(&vars, table, terraformName, "certfile", fmt.Sprintf("certificate file of dog link %s", row.Name), row.Connection.SSLOptions.CertFile)},
+					{"fail_if_no_peer_cert", row.Connection.SSLOptions.FailIfNoPeerCert},
+					{"keyfile", secret(&vars, table, terraformName, "keyfile", fmt.Sprintf("key file of dog link %s", row.Name), row.Connection.SSLOptions.KeyFile)},
+					{"server_name_indication", row.Connection.SSLOptions.ServerNameIndication},
+					{"verify", row.Connection.SSLOptions.Verify},
+				}
+			}
+			connection = object{
+				{"api_port", row.Connection.ApiPort},
+				{"host", row.Connection.Host},
+				{"password", secret(&vars, table, terraformName, "password", fmt.Sprintf("password of dog link %s", row.Name), row.Connection.Password)},
+				{"port", row.Connection.Port},
+				{"ssl_options", sslOptions},
+				{"user", row.Connection.User},
+				{"virtual_host", row.Connection.VirtualHost},
+			}
+		}
+		resources = append(resources, newResource("dog_link", terraformName, object{
+			{"address_handling", row.AddressHandling},
+			{"dog_connection", connection},
+			{"connection_type", row.ConnectionType},
+			{"direction", row.Direction},
+			{"enabled", row.Enabled},
+			{"name", row.Name},
+		}))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports, variables: vars}, nil
 }
 
-func importOutputFile(output_dir string, table string) *bufio.Writer {
-    import_f, err := os.Create(fmt.Sprintf("%s/%s_import.tf", output_dir, table))
-    check(err)
-    import_w := bufio.NewWriter(import_f)
+func host_export(x *exportContext, host_prefix string) (tableOutput, error) {
+	fmt.Printf("host_export\n")
+	table := "host"
+	hla := api.HostsListOptions{}
+	hla.Active = "true"
+	res, statusCode, err := x.c.GetHosts(&hla)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
 
-    return import_w
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	groupNames, err := x.tableNames("group")
+	if err != nil {
+		return tableOutput{}, fmt.Errorf("%s: %w", table, err)
+	}
+	for _, row := range res {
+		if !selected(row.Name) || !strings.HasPrefix(row.Name, host_prefix) {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		attrs := object{
+			{"environment", row.Environment},
+			{"group", x.referenceName(fmt.Sprintf("host '%s'", row.Name), "group", groupNames, row.Group, "name")},
+			{"hostkey", row.HostKey},
+			{"location", row.Location},
+			{"name", row.Name},
+		}
+		if row.AlertEnable != nil {
+			attrs = append(attrs, attribute{"alert_enable", *row.AlertEnable})
+		}
+		if row.Vars != nil {
+			attrs = append(attrs, attribute{"vars", jsonEncoded{row.Vars}})
+		}
+		resources = append(resources, newResource("dog_host", terraformName, attrs))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
 }
 
-func link_export(output_dir string, environment string) {
-    fmt.Printf("link_export\n")
-    table := "link"
-    import_w := importOutputFile(output_dir, table)
-
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
-
-    res, statusCode, err := c.GetLinks(nil)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName := toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_link\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "  address_handling = \"%s\"\n", row.AddressHandling)
-        fmt.Fprintf(tf_w, "  dog_connection   = {\n")
-        fmt.Fprintf(tf_w, "    api_port = %d\n", row.Connection.ApiPort)
-        fmt.Fprintf(tf_w, "    host     = \"%s\"\n", row.Connection.Host)
-        fmt.Fprintf(tf_w, "    password = \"%s\"\n", row.Connection.Password)
-        fmt.Fprintf(tf_w, "    port     = %d\n", row.Connection.Port)
-        fmt.Fprintf(tf_w, "    ssl_options = {\n")
-        fmt.Fprintf(tf_w, "        cacertfile             = \"%s\"\n", row.Connection.SSLOptions.CaCertFile)
-        fmt.Fprintf(tf_w, "        certfile               = \"%s\"\n", row.Connection.SSLOptions.CertFile)
-        fmt.Fprintf(tf_w, "        fail_if_no_peer_cert   = %t\n", row.Connection.SSLOptions.FailIfNoPeerCert)
-        fmt.Fprintf(tf_w, "        keyfile                = \"%s\"\n", row.Connection.SSLOptions.KeyFile)
-        fmt.Fprintf(tf_w, "        server_name_indication = \"%s\"\n", row.Connection.SSLOptions.ServerNameIndication)
-        fmt.Fprintf(tf_w, "        verify = \"%s\"\n", row.Connection.SSLOptions.Verify)
-        fmt.Fprintf(tf_w, "      },\n")
-        fmt.Fprintf(tf_w, "    user         = \"%s\"\n", row.Connection.User)
-        fmt.Fprintf(tf_w, "    virtual_host = \"%s\"\n", row.Connection.VirtualHost)
-        fmt.Fprintf(tf_w, "  }\n")
-        fmt.Fprintf(tf_w, "  connection_type = \"%s\"\n", row.ConnectionType)
-        fmt.Fprintf(tf_w, "  direction       = \"%s\"\n", row.Direction)
-        fmt.Fprintf(tf_w, "  enabled         = %t\n", row.Enabled)
-        fmt.Fprintf(tf_w, "  name            = \"%s\"\n", row.Name)
-        fmt.Fprintf(tf_w, "  provider        = dog.%s\n", environment)
-        fmt.Fprintf(tf_w, "}\n")
-        fmt.Fprintf(tf_w, "\n")
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_link.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
-}
+func group_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("group_export\n")
+	table := "group"
 
-func host_export(output_dir string, environment string, host_prefix string) {
-    fmt.Printf("host_export\n")
-    table := "host"
-    import_w := importOutputFile(output_dir, table)
+	res, statusCode, err := x.c.GetGroups(nil)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
 
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
-    hla := api.HostsListOptions{}                      
-	hla.Active = "true"
-	res, statusCode, err := c.GetHosts(&hla)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName :=  toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_host\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "  environment = \"%s\"\n", row.Environment)
-        fmt.Fprintf(tf_w, "  group       = dog_group.%s.name\n", row.Group)
-        fmt.Fprintf(tf_w, "  hostkey     = \"%s\"\n", row.HostKey)
-        fmt.Fprintf(tf_w, "  location    = \"%s\"\n", row.Location)
-        fmt.Fprintf(tf_w, "  name        = \"%s\"\n", row.Name)
-        fmt.Fprintf(tf_w, "  provider    = dog.%s\n", environment)
-		//fmt.Printf("variable val=%v is of type %v \n", row.AlertEnable, reflect.ValueOf(row.AlertEnable).Kind())
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	profileNames, err := x.tableNames("profile")
+	if err != nil {
+		return tableOutput{}, fmt.Errorf("%s: %w", table, err)
+	}
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
+		}
+		if row.ID == "all-active" {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		profileVersion := row.ProfileVersion
+		if profileVersion == "" {
+			profileVersion = "latest"
+		}
+		from := fmt.Sprintf("group '%s'", row.Name)
+		profileID := x.reference(from, "profile", profileNames, row.ProfileId, "id")
+		var profileName any = row.ProfileName
+		if ref, ok := profileID.(expr); ok {
+			profileName = expr(strings.TrimSuffix(string(ref), ".id") + ".name")
+		}
+		attrs := object{
+			{"description", row.Description},
+			{"name", row.Name},
+			{"profile_name", profileName},
+			{"profile_id", profileID},
+			{"profile_version", profileVersion},
+			{"ec2_security_group_ids", regionsgid_output(row.Ec2SecurityGroupIds)},
+		}
 		if row.AlertEnable != nil {
-		fmt.Fprintf(tf_w, "  alert_enable = %t\n", *row.AlertEnable)
+			attrs = append(attrs, attribute{"alert_enable", *row.AlertEnable})
 		}
-		if row.Vars == nil {
-		} else {
-		fmt.Fprintf(tf_w, "  vars = jsonencode({\n")
-        for key, val := range row.Vars {
-        fmt.Fprintf(tf_w, "    %s = %#v\n", key, val)
-        }
-        fmt.Fprintf(tf_w, "  })\n")
-		}
-        fmt.Fprintf(tf_w, "}\n")
-        fmt.Fprintf(tf_w, "\n")
-
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_host.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
+		if row.Vars != nil {
+			attrs = append(attrs, attribute{"vars", jsonEncoded{row.Vars}})
+		}
+		resources = append(resources, newResource("dog_group", terraformName, attrs))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
 }
 
-func group_export(output_dir string, environment string) {
-    fmt.Printf("group_export\n")
-    table := "group"
-    import_w := importOutputFile(output_dir, table)
-
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
-
-    res, statusCode, err := c.GetGroups(nil)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        if row.ID != "all-active" {
-            terraformName := toTerraformName(row.Name)
-            fmt.Fprintf(tf_w, "resource \"dog_group\" \"%s\" {\n", terraformName)
-            fmt.Fprintf(tf_w, "  description            = \"%s\"\n", row.Description)
-            fmt.Fprintf(tf_w, "  name                   = \"%s\"\n", row.Name)
-            fmt.Fprintf(tf_w, "  profile_name           = dog_profile.%s.name\n", row.ProfileName)
-            fmt.Fprintf(tf_w, "  profile_id             = dog_profile.%s.id\n", row.ProfileName)
-			if row.ProfileVersion == "" {
-            fmt.Fprintf(tf_w, "  profile_version        = \"latest\"\n")
-			} else {
-            fmt.Fprintf(tf_w, "  profile_version        = \"%s\"\n", row.ProfileVersion)
-			}
-            fmt.Fprintf(tf_w, "  ec2_security_group_ids = [\n")
-            regionsgid_output(tf_w, row.Ec2SecurityGroupIds)
-            fmt.Fprintf(tf_w, "  ]\n")
-            fmt.Fprintf(tf_w, "  provider = dog.%s\n", environment)
-			if row.AlertEnable != nil {
-			fmt.Fprintf(tf_w, "  alert_enable = %t\n", *row.AlertEnable)
-			}
-			if row.Vars == nil {
-			} else {
-            fmt.Fprintf(tf_w, "  vars = jsonencode({\n")
-            for key, val := range row.Vars {
-			if _, ok := val.(float64); ok {
-			fmt.Fprintf(tf_w, "          %s = %v\n", key, int(val.(float64)))
-			} else if _, ok := val.(bool); ok {
-			fmt.Fprintf(tf_w, "          %s = %t\n", key, val)
-			} else if _, ok := val.([]any); ok {
-			b, _ := json.Marshal(val)
-			fmt.Fprintf(tf_w, "          %s = %v\n", key, string(b))
-			} else {
-			fmt.Fprintf(tf_w, "          %s = %q\n", key, val)
-			}
-            }
-            fmt.Fprintf(tf_w, "  })\n")
-			} 
-            fmt.Fprintf(tf_w, "}\n")
-
-            fmt.Fprintf(tf_w, "\n")
-            //fmt.Fprintf(import_w, "terraform import module.dog.dog_group.%s %s\n", terraformName, row.ID)
-            fmt.Fprintf(import_w, "import {\n")
-            fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-            fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-            fmt.Fprintf(import_w, "}\n")
-        }
-    }
-    tf_w.Flush()
-    import_w.Flush()
+func regionsgid_output(ec2SecurityGroupIds []*api.Ec2SecurityGroupIds) []any {
+	list := []any{}
+	for _, region_sgid := range ec2SecurityGroupIds {
+		list = append(list, object{
+			{"region", region_sgid.Region},
+			{"sgid", region_sgid.SgId},
+		})
+	}
+	return list
 }
 
-func regionsgid_output(tf_w *bufio.Writer, ec2SecurityGroupIds []*api.Ec2SecurityGroupIds) {
-    for _, region_sgid := range ec2SecurityGroupIds {
-        fmt.Fprintf(tf_w, "      {\n")
-        fmt.Fprintf(tf_w, "        region = \"%s\"\n", region_sgid.Region)
-        fmt.Fprintf(tf_w, "        sgid   = \"%s\"\n", region_sgid.SgId)
-        fmt.Fprintf(tf_w, "      },\n")
-    }
-}
+func service_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("service_export\n")
+	table := "service"
 
-func service_export(output_dir string, environment string) {
-    fmt.Printf("service_export\n")
-    table := "service"
-    import_w := importOutputFile(output_dir, table)
-
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
-
-    res, statusCode, err := c.GetServices(nil)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName := toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_service\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "  name     = \"%s\"\n", row.Name)
-        fmt.Fprintf(tf_w, "  version  = \"%d\"\n", row.Version)
-        fmt.Fprintf(tf_w, "  services = [\n")
-        portprotocols_output(tf_w, row.Services)
-        fmt.Fprintf(tf_w, "  ]\n")
-        fmt.Fprintf(tf_w, "  provider = dog.%s\n", environment)
-        fmt.Fprintf(tf_w, "}\n")
-        fmt.Fprintf(tf_w, "\n")
-
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_service.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
-}
+	res, statusCode, err := x.c.GetServices(nil)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
 
-func portprotocols_output(tf_w *bufio.Writer, portProtocols []*api.PortProtocol) {
-    for _, port_protocol := range portProtocols {
-        fmt.Fprintf(tf_w, "      {\n")
-        fmt.Fprintf(tf_w, "        protocol = \"%s\"\n", port_protocol.Protocol)
-		if len(port_protocol.Ports)  == 0  {
-		fmt.Fprintf(tf_w, "        ports    = []\n")
-		} else {
-		Ports := `"`+strings.Join(port_protocol.Ports, `","`) + `"`
-		fmt.Fprintf(tf_w, "        ports    = [%s]\n", Ports)
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
 		}
-        fmt.Fprintf(tf_w, "      },\n")
-    }
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		resources = append(resources, newResource("dog_service", terraformName, object{
+			{"name", row.Name},
+			{"version", fmt.Sprintf("%d", row.Version)},
+			{"services", portprotocols_output(row.Services)},
+		}))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
 }
 
-func zone_export(output_dir string, environment string) {
-    fmt.Printf("zone_export\n")
-    table := "zone"
-    import_w := importOutputFile(output_dir, table)
-
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
-
-    res, statusCode, err := c.GetZones(nil)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName := toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_zone\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "  name           = \"%s\"\n", row.Name)
-        //fmt.Fprintf(tf_w, strings.ReplaceAll(fmt.Sprintf("  ipv4_addresses = %q\n", row.IPv4Addresses), "\" \"", "\",\""))
-
-		if reflect.DeepEqual(row.IPv4Addresses, []string{}) {
-		fmt.Fprintf(tf_w, "  ipv4_addresses = []\n")
-		} else if reflect.DeepEqual(row.IPv4Addresses, []string{""}) {
-		fmt.Fprintf(tf_w, "  ipv4_addresses = []\n")
-		} else {
-		IPv4Addresses := `"`+strings.Join(row.IPv4Addresses, `","`) + `"`
-		fmt.Fprintf(tf_w, "  ipv4_addresses = [%s]\n", IPv4Addresses)
+func portprotocols_output(portProtocols []*api.PortProtocol) []any {
+	list := []any{}
+	for _, port_protocol := range portProtocols {
+		list = append(list, object{
+			{"protocol", port_protocol.Protocol},
+			{"ports", stringList(port_protocol.Ports)},
+		})
+	}
+	return list
+}
+
+// addresses drops the empty entries dog returns for zones without
+// addresses.
+func addresses(values []string) []any {
+	list := []any{}
+	for _, value := range values {
+		if value != "" {
+			list = append(list, value)
 		}
-		if reflect.DeepEqual(row.IPv6Addresses, []string{}) {
-		fmt.Fprintf(tf_w, "  ipv6_addresses = []\n")
-		} else if reflect.DeepEqual(row.IPv6Addresses, []string{""}) {
-		fmt.Fprintf(tf_w, "  ipv6_addresses = []\n")
-		} else {
-		IPv6Addresses := `"`+strings.Join(row.IPv6Addresses, `","`) + `"`
-		fmt.Fprintf(tf_w, "  ipv6_addresses = [%s]\n", IPv6Addresses)
-		}
-        //fmt.Fprintf(tf_w, strings.ReplaceAll(fmt.Sprintf("  ipv6_addresses = %q\n", row.IPv6Addresses), "\" \"", "\",\""))
-        fmt.Fprintf(tf_w, "  provider       = dog.%s\n", environment)
-        fmt.Fprintf(tf_w, "}\n")
-        fmt.Fprintf(tf_w, "\n")
-
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_zone.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
+	}
+	return list
 }
 
-func ruleset_export(output_dir string, environment string) {
-    fmt.Printf("ruleset_export\n")
-    table := "ruleset"
-    import_w := importOutputFile(output_dir, table)
+func zone_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("zone_export\n")
+	table := "zone"
 
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
+	res, statusCode, err := x.c.GetZones(nil)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
 
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		resources = append(resources, newResource("dog_zone", terraformName, object{
+			{"name", row.Name},
+			{"ipv4_addresses", addresses(row.IPv4Addresses)},
+			{"ipv6_addresses", addresses(row.IPv6Addresses)},
+		}))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
+}
+
+func ruleset_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("ruleset_export\n")
+	table := "ruleset"
 
-    options := api.RulesetsListOptions{} 
-    options.Names = true
+	options := api.RulesetsListOptions{}
 	options.Active = true
-    res, statusCode, err := c.GetRulesets(&options)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName := toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_ruleset\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "  name       = \"%s\"\n", row.Name)
-        fmt.Fprintf(tf_w, "  profile_id = dog_profile.%s.id\n", row.Name)
-        fmt.Fprintf(tf_w, "  rules      = {\n")
-        inbound := row.Rules.Inbound
-        fmt.Fprintf(tf_w, "    inbound  = [\n")
-        rules_output(tf_w, inbound)
-        fmt.Fprintf(tf_w, "    ]\n")
-        fmt.Fprintf(tf_w, "    outbound = [\n")
-        outbound := row.Rules.Outbound
-        rules_output(tf_w, outbound)
-        fmt.Fprintf(tf_w, "    ]\n")
-        fmt.Fprintf(tf_w, "  }\n")
-        fmt.Fprintf(tf_w, "  provider   = dog.%s\n", environment)
-        fmt.Fprintf(tf_w, "}\n")
-
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_ruleset.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
-}
+	res, statusCode, err := x.c.GetRulesets(&options)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
 
-func profile_export(output_dir string, environment string) {
-    fmt.Printf("profile_export\n")
-    table := "profile"
-    import_w := importOutputFile(output_dir, table)
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	var refs ruleNames
+	if refs.profiles, err = x.tableNames("profile"); err != nil {
+		return tableOutput{}, fmt.Errorf("%s: %w", table, err)
+	}
+	if refs.zones, err = x.tableNames("zone"); err != nil {
+		return tableOutput{}, fmt.Errorf("%s: %w", table, err)
+	}
+	if refs.groups, err = x.tableNames("group"); err != nil {
+		return tableOutput{}, fmt.Errorf("%s: %w", table, err)
+	}
+	if refs.services, err = x.tableNames("service"); err != nil {
+		return tableOutput{}, fmt.Errorf("%s: %w", table, err)
+	}
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		from := fmt.Sprintf("ruleset '%s'", row.Name)
+		rules := object{}
+		if row.Rules != nil {
+			rules = object{
+				{"inbound", x.rules_output(from, row.Rules.Inbound, refs)},
+				{"outbound", x.rules_output(from, row.Rules.Outbound, refs)},
+			}
+		}
+		attrs := object{{"name", row.Name}}
+		if row.ProfileId != nil {
+			attrs = append(attrs, attribute{"profile_id", x.reference(from, "profile", refs.profiles, *row.ProfileId, "id")})
+		}
+		attrs = append(attrs, attribute{"rules", rules})
+		resources = append(resources, newResource("dog_ruleset", terraformName, attrs))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
+}
 
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
+func profile_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("profile_export\n")
+	table := "profile"
 
-    options := api.ProfilesListOptions{} 
+	options := api.ProfilesListOptions{}
 	options.Active = true
-    res, statusCode, err := c.GetProfiles(&options)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName := toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_profile\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "  name     = \"%s\"\n", row.Name)
-        fmt.Fprintf(tf_w, "  version  = \"%s\"\n", row.Version)
-        fmt.Fprintf(tf_w, "  provider = dog.%s\n", environment)
-        fmt.Fprintf(tf_w, "}\n")
-
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_profile.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
+	res, statusCode, err := x.c.GetProfiles(&options)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
+
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		resources = append(resources, newResource("dog_profile", terraformName, object{
+			{"name", row.Name},
+			{"version", row.Version},
+		}))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
 }
 
-func rules_output(tf_w *bufio.Writer, rules []*api.Rule) {
-    for _, rule := range rules {
-        groupTerraformName := toTerraformName(rule.Group)
-        serviceTerraformName := toTerraformName(rule.Service)
-        fmt.Fprintf(tf_w, "      {\n")
-        fmt.Fprintf(tf_w, "        action       = \"%s\"\n", rule.Action)
-        fmt.Fprintf(tf_w, "        active       = \"%t\"\n", rule.Active)
-        fmt.Fprintf(tf_w, "        comment      = \"%s\"\n", rule.Comment)
-        fmt.Fprintf(tf_w, strings.ReplaceAll(
-	      fmt.Sprintf("        environments = %q\n", rule.Environments), "\" \"", "\",\""))
-        if rule.Group == "any" {
-        fmt.Fprintf(tf_w, "        group       = \"any\"\n")
-		} else if rule.Group == "all-active" {
-        fmt.Fprintf(tf_w, "        group       = \"all-active\"\n")
-        } else {
-            if rule.GroupType == "ZONE" {
-        fmt.Fprintf(tf_w, "        group       = dog_zone.%s.id\n", groupTerraformName)
-            } else {
-        fmt.Fprintf(tf_w, "        group       = dog_group.%s.id\n", groupTerraformName)
-            }
-        }
-        fmt.Fprintf(tf_w, "        group_type   = \"%s\"\n", rule.GroupType)
-        fmt.Fprintf(tf_w, "        interface    = \"%s\"\n", rule.Interface)
-        fmt.Fprintf(tf_w, "        log = \"%t\"\n", rule.Log)
-        fmt.Fprintf(tf_w, "        log_prefix   = \"%s\"\n", rule.LogPrefix)
-        if serviceTerraformName == "any" {
-        fmt.Fprintf(tf_w, "        service      = \"%s\"\n", serviceTerraformName)
-        } else {
-        fmt.Fprintf(tf_w, "        service      = dog_service.%s.id\n", serviceTerraformName)
-        }
-        fmt.Fprintf(tf_w, strings.ReplaceAll(
-	      fmt.Sprintf("        states      = %q\n", rule.States), "\" \"", "\",\""))
-        fmt.Fprintf(tf_w, "        type        = \"%s\"\n", rule.Type)
-
-        fmt.Fprintf(tf_w, "      },\n")
-    }
+// ruleNames holds the Terraform names of the objects rulesets reference.
+type ruleNames struct {
+	profiles *terraformNames
+	zones    *terraformNames
+	groups   *terraformNames
+	services *terraformNames
 }
 
-func fact_export(output_dir string, environment string) {
-    fmt.Printf("fact_export\n")
-    table := "fact"
-    import_w := importOutputFile(output_dir, table)
-
-    c := api.NewClient(os.Getenv("DOG_API_TOKEN"), os.Getenv("DOG_API_ENDPOINT"))
-
-    res, statusCode, err := c.GetFacts(nil)
-    if err != nil {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-    if statusCode != 200 {
-        log.Fatalln("res: ", res, "statusCode: ", statusCode, "err: ", err)
-    }
-
-    tf_w := terraformOutputFile(output_dir, table)
-    for _, row := range res {
-        terraformName := toTerraformName(row.Name)
-        fmt.Fprintf(tf_w, "resource \"dog_fact\" \"%s\" {\n", terraformName)
-        fmt.Fprintf(tf_w, "    name         = %q\n", row.Name)
-        fmt.Fprintf(tf_w, "    groups       = {\n")
-		for name, group := range row.Groups {
-			fmt.Fprintf(tf_w, "      %s = {\n", name)
-
-			if interfaceIsSlice(group.Children) {
-				stringsJson, err := json.Marshal(group.Children)
-				if err != nil {
-					log.Fatal("Cannot encode to JSON ", err)
-				}
-				//fmt.Printf("len(stringsJson)=%d\n", len(stringsJson))
-				if string(stringsJson) == "null" {
-					fmt.Fprintf(tf_w, "        children = []\n")
-				} else {
-					fmt.Fprintf(tf_w, "        children = %s\n", stringsJson)
-				}
-			} else {
-				fmt.Fprintf(tf_w, "        children = %q\n", group.Children)
+func (x *exportContext) rules_output(from string, rules []*api.Rule, refs ruleNames) []any {
+	list := []any{}
+	for _, rule := range rules {
+		var group any
+		if rule.Group == "any" || rule.Group == "all-active" {
+			group = rule.Group
+		} else if rule.GroupType == "ZONE" {
+			group = x.reference(from, "zone", refs.zones, rule.Group, "id")
+		} else {
+			group = x.reference(from, "group", refs.groups, rule.Group, "id")
+		}
+		var service any
+		if rule.Service == "any" {
+			service = rule.Service
+		} else {
+			service = x.reference(from, "service", refs.services, rule.Service, "id")
+		}
+		list = append(list, object{
+			{"action", rule.Action},
+			{"active", rule.Active},
+			{"comment", rule.Comment},
+			{"environments", stringList(rule.Environments)},
+			{"group", group},
+			{"group_type", rule.GroupType},
+			{"interface", rule.Interface},
+			{"log", rule.Log},
+			{"log_prefix", rule.LogPrefix},
+			{"service", service},
+			{"states", stringList(rule.States)},
+			{"type", rule.Type},
+		})
+	}
+	return list
+}
+
+func fact_export(x *exportContext) (tableOutput, error) {
+	fmt.Printf("fact_export\n")
+	table := "fact"
+
+	res, statusCode, err := x.c.GetFacts(nil)
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return tableOutput{}, err
+	}
+
+	resources := []resourceBlock{}
+	imports := []importBlock{}
+	objects := []namedObject{}
+	for _, row := range res {
+		objects = append(objects, namedObject{row.ID, row.Name})
+	}
+	names := x.listedNames(table, objects)
+	for _, row := range res {
+		if !selected(row.Name) {
+			continue
+		}
+		terraformName := names.ID(row.ID, row.Name)
+		x.recordName(table, row.ID, row.Name, terraformName)
+		groupNames := []string{}
+		for name := range row.Groups {
+			groupNames = append(groupNames, name)
+		}
+		slices.Sort(groupNames)
+		groups := object{}
+		for _, name := range groupNames {
+			group := row.Groups[name]
+			factGroup := object{
+				{"children", stringList(group.Children)},
 			}
-			if group.Hosts == nil {
-			} else {
-				fmt.Fprintf(tf_w, "        hosts = jsonencode({\n")
+			if group.Hosts != nil {
+				hosts := map[string]any{}
 				for host, hostValues := range group.Hosts {
-					fmt.Fprintf(tf_w, "          \"%s\"     = {\n", host)
-					for key, val := range hostValues {
-						if _, ok := val.(float64); ok {
-							fmt.Fprintf(tf_w, "            \"%s\" = %#v\n", key, int(val.(float64)))
-						} else if _, ok := val.(bool); ok {
-							fmt.Fprintf(tf_w, "            \"%s\" = %#v\n", key, bool(val.(bool)))
-						} else {
-							fmt.Fprintf(tf_w, "            \"%s\" = %q\n", key, val)
-						}
-					}
-					fmt.Fprintf(tf_w, "          }\n")
+					hosts[host] = hostValues
 				}
-				fmt.Fprintf(tf_w, "        })\n")
+				factGroup = append(factGroup, attribute{"hosts", jsonEncoded{hosts}})
 			}
-			if group.Vars == nil {
-			} else {
-				fmt.Fprintf(tf_w, "        vars = jsonencode({\n")
-				for key, val := range group.Vars {
-					if _, ok := val.(float64); ok {
-						fmt.Fprintf(tf_w, "          %s = %#v\n", key, int(val.(float64)))
-					} else if _, ok := val.(bool); ok {
-						fmt.Fprintf(tf_w, "          %s = %#v\n", key, bool(val.(bool)))
-					} else if interfaceIsSlice(val) {
-						stringsJson, err := json.Marshal(val)
-						if err != nil {
-							log.Fatal("Cannot encode to JSON ", err)
-						}
-						fmt.Fprintf(tf_w, "          %s = %s\n", key, stringsJson)
-					} else {
-						fmt.Fprintf(tf_w, "          %s = %q\n", key, val)
-					}
-				}
-				fmt.Fprintf(tf_w, "      })\n")
+			if group.Vars != nil {
+				factGroup = append(factGroup, attribute{"vars", jsonEncoded{group.Vars}})
 			}
-			fmt.Fprintf(tf_w, "    }\n")
-		}
-		fmt.Fprintf(tf_w, "  }\n")
-		fmt.Fprintf(tf_w, "}\n")
-        //fmt.Fprintf(import_w, "terraform import module.dog.dog_fact.%s %s\n", terraformName, row.ID)
-        fmt.Fprintf(import_w, "import {\n")
-        fmt.Fprintf(import_w, "  id = %q\n", row.ID)
-        fmt.Fprintf(import_w, "  to = module.dog.dog_%s.%s\n", table, terraformName)
-        fmt.Fprintf(import_w, "}\n")
-    }
-    tf_w.Flush()
-    import_w.Flush()
+			groups = append(groups, attribute{name, factGroup})
+		}
+		resources = append(resources, newResource("dog_fact", terraformName, object{
+			{"name", row.Name},
+			{"groups", groups},
+		}))
+		imports = append(imports, importBlock{ID: row.ID, To: importAddress(x.module, table, terraformName)})
+	}
+	return tableOutput{table: table, resources: resources, imports: imports}, nil
 }
 
-func interfaceIsSlice(t interface{}) bool {
-	switch reflect.TypeOf(t).Kind() {
-	case reflect.Slice:
-		return true
-	default:
+// selected reports whether an object name passes the -include and -exclude
+// filters.
+func selected(name string) bool {
+	if include_re != nil && !include_re.MatchString(name) {
+		return false
+	}
+	if exclude_re != nil && exclude_re.MatchString(name) {
 		return false
 	}
+	return true
 }
 
+// importAddress returns the resource address used in the "to" argument of an
+// import block, prefixed with the target module unless exporting to the root
+// module.
+func importAddress(module string, table string, terraformName string) string {
+	if module == "" || module == "root" {
+		return fmt.Sprintf("dog_%s.%s", table, terraformName)
+	}
+	return fmt.Sprintf("%s.dog_%s.%s", module, table, terraformName)
+}
+
+var all_tables = []string{"group", "host", "link", "ruleset", "profile", "service", "zone", "fact"}
+
 var environment string
+var environments string
 var output_dir string
 var host_prefix string
+var tables string
+var include string
+var exclude string
+var module_address string
+var provider_alias string
+var format string
+var secret_vars string
+var parallelism int
+var plaintext_secrets bool
+
+var include_re *regexp.Regexp
+var exclude_re *regexp.Regexp
+var provider_address string
+var export_tables []string
+var environment_list []string
+
 func init() {
-    log.SetFlags(log.LstdFlags | log.Lshortfile)
-    flag.StringVar(&environment, "environment", "", "dog environment")
-    flag.StringVar(&output_dir, "output_dir", "", "base dir for output")
-    flag.StringVar(&host_prefix, "host_prefix", "", "prefix for host names")
-    flag.Parse()
-    if environment == "" {
-        fmt.Fprintf(os.Stderr, "missing required -environment argument/flag\n")
-        os.Exit(2)
-    }
-    if output_dir == "" {
-        fmt.Fprintf(os.Stderr, "missing required -output_dir argument/flag\n")
-        os.Exit(2)
-    }
-    if host_prefix == "" {
-        fmt.Fprintf(os.Stderr, "missing required -host_prefix argument/flag\n")
-        os.Exit(2)
-    }
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+}
+
+// filterFlags registers the table and name filters shared by every command.
+func filterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&tables, "tables", strings.Join(all_tables, ","), "comma separated list of tables")
+	fs.StringVar(&include, "include", "", "only include objects whose name matches this regular expression")
+	fs.StringVar(&exclude, "exclude", "", "skip objects whose name matches this regular expression")
+}
+
+// parseFilterFlags validates the filter flags, exiting with status 2 on bad
+// input like flag.Parse does.
+func parseFilterFlags() {
+	export_tables = nil
+	for _, table := range strings.Split(tables, ",") {
+		table = strings.TrimSpace(table)
+		if table == "" {
+			continue
+		}
+		if !slices.Contains(all_tables, table) {
+			fmt.Fprintf(os.Stderr, "unknown table '%s' in -tables, must be one of: %s\n", table, strings.Join(all_tables, ","))
+			os.Exit(2)
+		}
+		export_tables = append(export_tables, table)
+	}
+	var err error
+	if include != "" {
+		if include_re, err = regexp.Compile(include); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -include regular expression: %s\n", err)
+			os.Exit(2)
+		}
+	}
+	if exclude != "" {
+		if exclude_re, err = regexp.Compile(exclude); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -exclude regular expression: %s\n", err)
+			os.Exit(2)
+		}
+	}
+}
+
+func parseExportFlags(args []string) {
+	fs := flag.NewFlagSet("dog-import", flag.ExitOnError)
+	fs.StringVar(&environment, "environment", "", "dog environment")
+	fs.StringVar(&environments, "environments", "", "comma separated list of dog environments to consolidate into one module")
+	fs.StringVar(&output_dir, "output_dir", "", "base dir for output")
+	fs.StringVar(&host_prefix, "host_prefix", "", "only export hosts whose name starts with this prefix")
+	filterFlags(fs)
+	profileFlag(fs)
+	fs.StringVar(&module_address, "module", "module.dog", "module address used in import blocks, 'root' for the root module")
+	fs.StringVar(&provider_alias, "provider_alias", "", "provider alias written to each resource, defaults to -environment, 'none' to omit")
+	fs.StringVar(&format, "format", "hcl", "output format: hcl (.tf) or json (.tf.json)")
+	fs.StringVar(&secret_vars, "secret_vars", "", "write the values of the generated secret variables to this tfvars file (mode 0600)")
+	fs.BoolVar(&plaintext_secrets, "plaintext_secrets", false, "write passwords and certificate paths into the resources instead of variables")
+	fs.IntVar(&parallelism, "parallelism", 4, "number of tables exported at the same time")
+	fs.Parse(args)
+	if environment != "" && environments != "" {
+		fmt.Fprintf(os.Stderr, "-environment can't be used with -environments\n")
+		os.Exit(2)
+	}
+	if environments != "" && profile_name != "" {
+		fmt.Fprintf(os.Stderr, "-profile can't be used with -environments\n")
+		os.Exit(2)
+	}
+	if environment == "" && environments == "" {
+		fmt.Fprintf(os.Stderr, "missing required -environment argument/flag\n")
+		os.Exit(2)
+	}
+	if output_dir == "" {
+		fmt.Fprintf(os.Stderr, "missing required -output_dir argument/flag\n")
+		os.Exit(2)
+	}
+	if format != "hcl" && format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown -format '%s', must be hcl or json\n", format)
+		os.Exit(2)
+	}
+	if parallelism < 1 {
+		fmt.Fprintf(os.Stderr, "-parallelism must be at least 1\n")
+		os.Exit(2)
+	}
+	if plaintext_secrets && secret_vars != "" {
+		fmt.Fprintf(os.Stderr, "-secret_vars can't be used with -plaintext_secrets\n")
+		os.Exit(2)
+	}
+	parseFilterFlags()
+	if environments != "" {
+		parseEnvironments()
+		return
+	}
+	switch provider_alias {
+	case "":
+		provider_address = "dog." + environment
+	case "none":
+		provider_address = ""
+	default:
+		provider_address = "dog." + provider_alias
+	}
 }
 
 func main() {
-    fmt.Printf("host_prefix: '%s'\n", host_prefix)
-    group_export(output_dir, environment)
-    host_export(output_dir, environment, host_prefix)
-    link_export(output_dir, environment) //TODO disabled while testing
-    ruleset_export(output_dir, environment)
-    profile_export(output_dir, environment)
-    service_export(output_dir, environment)
-    zone_export(output_dir, environment)
-    fact_export(output_dir, environment)
-    fmt.Printf("check %s/ for output files\n", output_dir)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "diff":
+			os.Exit(runDiff(os.Args[2:]))
+		case "backup":
+			os.Exit(runBackup(os.Args[2:]))
+		case "restore":
+			os.Exit(runRestore(os.Args[2:]))
+		}
+	}
+	parseExportFlags(os.Args[1:])
+	fmt.Printf("host_prefix: '%s'\n", host_prefix)
+	if err := os.MkdirAll(output_dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	var errs []error
+	if len(environment_list) > 0 {
+		errs = consolidate(environment_list)
+	} else {
+		c, err := newClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		x := newExportContext(c, environment, module_address)
+		var outputs []tableOutput
+		outputs, errs = runExporters(x, export_tables, parallelism)
+		for _, out := range outputs {
+			if err := writeTable(output_dir, out); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := writeNameMappings(output_dir); err != nil {
+		errs = append(errs, err)
+	}
+	if secret_vars != "" {
+		if err := writeSecretValues(secret_vars); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Printf("secret values written to %s\n", secret_vars)
+		}
+	}
+	if plaintext_secrets {
+		fmt.Printf("WARNING: link passwords and certificate paths were written in plaintext\n")
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "export failed with %d error(s):\n", len(errs))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "  %s\n", err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("check %s/ for output files\n", output_dir)
+}
+
+func exportTable(x *exportContext, table string) (tableOutput, error) {
+	switch table {
+	case "group":
+		return group_export(x)
+	case "host":
+		return host_export(x, host_prefix)
+	case "link":
+		return link_export(x)
+	case "ruleset":
+		return ruleset_export(x)
+	case "profile":
+		return profile_export(x)
+	case "service":
+		return service_export(x)
+	case "zone":
+		return zone_export(x)
+	case "fact":
+		return fact_export(x)
+	}
+	return tableOutput{}, fmt.Errorf("unknown table %s", table)
+}
+
+// runExporters exports the given tables with at most parallelism running at
+// once. It returns the output of every table that succeeded and every error,
+// both in table order.
+func runExporters(x *exportContext, tables []string, parallelism int) ([]tableOutput, []error) {
+	outputs := make([]tableOutput, len(tables))
+	results := make([]error, len(tables))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outputs[i], results[i] = exportTable(x, tables[i])
+			}
+		}()
+	}
+	for i := range tables {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	succeeded := []tableOutput{}
+	errs := []error{}
+	for i, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			succeeded = append(succeeded, outputs[i])
+		}
+	}
+	return succeeded, errs
 }
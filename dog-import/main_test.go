@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// fakeAPI serves the recorded dog API responses in testdata/api, one
+// <path>.json file per list endpoint.
+func fakeAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	return fakeAPIWith(t, nil)
+}
+
+// fakeAPIWith is fakeAPI with the responses of some endpoints replaced, by
+// path.
+func fakeAPIWith(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "dog-import only reads", http.StatusMethodNotAllowed)
+			return
+		}
+		path := strings.Trim(r.URL.Path, "/")
+		if response, ok := responses[path]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(response))
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "api", path+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// exportFormat runs every exporter against the fake API with the given
+// -format and returns the directory the files were written to.
+func exportFormat(t *testing.T, endpoint string, outputFormat string) string {
+	t.Helper()
+	dir := t.TempDir()
+	format = outputFormat
+	provider_address = "dog.qa"
+	module_address = "module.dog"
+	export_tables = all_tables
+	include_re, exclude_re = nil, nil
+	plaintext_secrets = false
+	x := newExportContext(api.NewClient("token", endpoint), "qa", module_address)
+	outputs, errs := runExporters(x, export_tables, 4)
+	for _, err := range errs {
+		t.Errorf("export: %s", err)
+	}
+	for _, out := range outputs {
+		if err := writeTable(dir, out); err != nil {
+			t.Errorf("write %s: %s", out.table, err)
+		}
+	}
+	return dir
+}
+
+func TestExportGolden(t *testing.T) {
+	server := fakeAPI(t)
+	for _, outputFormat := range []string{"hcl", "json"} {
+		t.Run(outputFormat, func(t *testing.T) {
+			dir := exportFormat(t, server.URL, outputFormat)
+			checkGolden(t, dir, filepath.Join("testdata", "golden", outputFormat))
+		})
+	}
+}
+
+// checkGolden compares the files written to dir with the golden files, or
+// replaces the golden files with them when -update is given.
+func checkGolden(t *testing.T, dir string, golden string) {
+	t.Helper()
+	if *update {
+		if err := os.RemoveAll(golden); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(golden, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := map[string]bool{}
+	for _, file := range files {
+		written[file.Name()] = true
+		got, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(golden, file.Name())
+		if *update {
+			if err := os.WriteFile(path, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: unexpected file, run go test -update to accept it", file.Name())
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from %s, run go test -update to accept it\n--- got\n%s\n--- want\n%s", file.Name(), path, got, want)
+		}
+	}
+	goldenFiles, err := os.ReadDir(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range goldenFiles {
+		if !written[file.Name()] {
+			t.Errorf("%s: not written by the export", file.Name())
+		}
+		if filepath.Ext(file.Name()) != ".tf" {
+			continue
+		}
+		want, err := os.ReadFile(filepath.Join(golden, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if formatted := hclwrite.Format(want); !bytes.Equal(formatted, want) {
+			t.Errorf("%s is not formatted the way terraform fmt does\n--- got\n%s\n--- formatted\n%s", file.Name(), want, formatted)
+		}
+	}
+}
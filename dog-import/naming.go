@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+var invalidNameCharsRe = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// toTerraformName turns a dog object name into a valid Terraform identifier:
+// anything other than letters, digits, '_' and '-' becomes '_', and names
+// that don't start with a letter or '_' are prefixed with '_'.
+func toTerraformName(name string) string {
+	tfName := invalidNameCharsRe.ReplaceAllString(name, "_")
+	if tfName == "" {
+		return "_"
+	}
+	first := tfName[0]
+	if !(first == '_' || (first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z')) {
+		tfName = "_" + tfName
+	}
+	return tfName
+}
+
+type namedObject struct {
+	ID   string
+	Name string
+}
+
+// terraformNames maps the objects of one table to unique Terraform names.
+type terraformNames struct {
+	byID   map[string]string
+	byName map[string]string
+	// dogNames holds the dog name of every object by ID
+	dogNames map[string]string
+}
+
+// newTerraformNames assigns names independently of the order the API returns
+// objects in. When several objects sanitize to the same name, an object whose
+// name is already a valid identifier keeps it, and the others get _2, _3, ...
+// suffixes ordered by name and then ID.
+func newTerraformNames(objects []namedObject) *terraformNames {
+	sorted := append([]namedObject{}, objects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		bi, bj := toTerraformName(sorted[i].Name), toTerraformName(sorted[j].Name)
+		if bi != bj {
+			return bi < bj
+		}
+		ei, ej := bi == sorted[i].Name, bj == sorted[j].Name
+		if ei != ej {
+			return ei
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	taken := map[string]bool{}
+	for _, obj := range sorted {
+		taken[toTerraformName(obj.Name)] = true
+	}
+
+	names := &terraformNames{byID: map[string]string{}, byName: map[string]string{}, dogNames: map[string]string{}}
+	used := map[string]bool{}
+	for _, obj := range sorted {
+		base := toTerraformName(obj.Name)
+		tfName := base
+		for n := 2; used[tfName] || (tfName != base && taken[tfName]); n++ {
+			tfName = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[tfName] = true
+		names.byID[obj.ID] = tfName
+		names.dogNames[obj.ID] = obj.Name
+		if _, ok := names.byName[obj.Name]; !ok {
+			names.byName[obj.Name] = tfName
+		}
+	}
+	return names
+}
+
+// ID returns the Terraform name of the object with the given ID.
+func (n *terraformNames) ID(id string, name string) string {
+	if tfName, ok := n.byID[id]; ok {
+		return tfName
+	}
+	return toTerraformName(name)
+}
+
+// Name returns the Terraform name of the object with the given dog name, for
+// references between tables.
+func (n *terraformNames) Name(name string) string {
+	if tfName, ok := n.byName[name]; ok {
+		return tfName
+	}
+	return toTerraformName(name)
+}
+
+// namesEntry computes the names of one table once, however many exporters
+// ask for them concurrently.
+type namesEntry struct {
+	once  sync.Once
+	names *terraformNames
+	err   error
+}
+
+// exportContext is one dog instance being exported: its client, the
+// environment it belongs to, the module its import blocks target and the
+// Terraform names of its objects.
+type exportContext struct {
+	c           *api.Client
+	environment string
+	module      string
+
+	namesMu sync.Mutex
+	names   map[string]*namesEntry
+}
+
+func newExportContext(c *api.Client, environment string, module string) *exportContext {
+	return &exportContext{c: c, environment: environment, module: module, names: map[string]*namesEntry{}}
+}
+
+func (x *exportContext) namesEntry(table string) *namesEntry {
+	x.namesMu.Lock()
+	defer x.namesMu.Unlock()
+	entry, ok := x.names[table]
+	if !ok {
+		entry = &namesEntry{}
+		x.names[table] = entry
+	}
+	return entry
+}
+
+// setNames fixes the names of a table up front, for exports that must agree
+// with each other on names.
+func (x *exportContext) setNames(table string, names *terraformNames) {
+	entry := x.namesEntry(table)
+	entry.once.Do(func() {
+		entry.names = names
+	})
+}
+
+// tableNames returns the Terraform names of every object in a table, listing
+// the table from the API the first time it is needed.
+func (x *exportContext) tableNames(table string) (*terraformNames, error) {
+	entry := x.namesEntry(table)
+	entry.once.Do(func() {
+		var objects []namedObject
+		objects, entry.err = fetchNamedObjects(x.c, table)
+		entry.names = newTerraformNames(objects)
+	})
+	return entry.names, entry.err
+}
+
+// listedNames is tableNames for an exporter that has already listed its own
+// table, so the table isn't fetched twice.
+func (x *exportContext) listedNames(table string, objects []namedObject) *terraformNames {
+	entry := x.namesEntry(table)
+	entry.once.Do(func() {
+		entry.names = newTerraformNames(objects)
+	})
+	if entry.err != nil {
+		// another exporter failed to list the table, these objects are
+		// complete
+		return newTerraformNames(objects)
+	}
+	return entry.names
+}
+
+func fetchNamedObjects(c *api.Client, table string) ([]namedObject, error) {
+	objects := []namedObject{}
+	var statusCode int
+	var err error
+	switch table {
+	case "group":
+		var res api.GroupsListJson
+		res, statusCode, err = c.GetGroups(nil)
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "host":
+		var res api.HostsListJson
+		res, statusCode, err = c.GetHosts(&api.HostsListOptions{Active: "true"})
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "link":
+		var res api.LinksList
+		res, statusCode, err = c.GetLinks(nil)
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "ruleset":
+		var res api.RulesetsList
+		res, statusCode, err = c.GetRulesets(&api.RulesetsListOptions{Names: true, Active: true})
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "profile":
+		var res api.ProfilesList
+		res, statusCode, err = c.GetProfiles(&api.ProfilesListOptions{Active: true})
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "service":
+		var res api.ServicesList
+		res, statusCode, err = c.GetServices(nil)
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "zone":
+		var res api.ZonesList
+		res, statusCode, err = c.GetZones(nil)
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	case "fact":
+		var res api.FactsListJson
+		res, statusCode, err = c.GetFacts(nil)
+		for _, row := range res {
+			objects = append(objects, namedObject{row.ID, row.Name})
+		}
+	}
+	if err := apiStatus(table, statusCode, err, 200); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+type nameMapping struct {
+	Environment   string
+	Table         string
+	ID            string
+	Name          string
+	TerraformName string
+	Address       string
+}
+
+var (
+	mappingsMu sync.Mutex
+	mappings   []nameMapping
+)
+
+// recordName adds an exported object to the name mapping report.
+func (x *exportContext) recordName(table string, id string, name string, tfName string) {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	mappings = append(mappings, nameMapping{x.environment, table, id, name, tfName, importAddress(x.module, table, tfName)})
+}
+
+// exported reports whether an object is written by this export, so that
+// other objects can reference it.
+func exported(table string, id string, name string) bool {
+	if table == "group" && id == "all-active" {
+		return false
+	}
+	return slices.Contains(export_tables, table) && selected(name)
+}
+
+// reference returns a reference to an attribute of the object of table with
+// the given ID. Objects that aren't exported can't be referenced, so their ID
+// is written instead, with a warning.
+func (x *exportContext) reference(from string, table string, names *terraformNames, id string, attr string) any {
+	name, ok := names.dogNames[id]
+	if !ok || !exported(table, id, name) {
+		fmt.Printf("WARNING: %s references %s %s, which is not exported, writing its ID\n", from, table, id)
+		return id
+	}
+	return expr(fmt.Sprintf("dog_%s.%s.%s", table, names.ID(id, name), attr))
+}
+
+// referenceName is reference for objects referenced by name, which is
+// written instead when the object isn't exported.
+func (x *exportContext) referenceName(from string, table string, names *terraformNames, name string, attr string) any {
+	for id, dogName := range names.dogNames {
+		if dogName == name && exported(table, id, name) {
+			return expr(fmt.Sprintf("dog_%s.%s.%s", table, names.Name(name), attr))
+		}
+	}
+	fmt.Printf("WARNING: %s references %s '%s', which is not exported, writing its name\n", from, table, name)
+	return name
+}
+
+// writeNameMappings writes terraform_names.csv listing every exported
+// object's dog name next to its Terraform address, and prints the objects
+// whose name had to be changed.
+func writeNameMappings(output_dir string) error {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	sort.SliceStable(mappings, func(i, j int) bool {
+		if mappings[i].Table != mappings[j].Table {
+			return mappings[i].Table < mappings[j].Table
+		}
+		if mappings[i].Address != mappings[j].Address {
+			return mappings[i].Address < mappings[j].Address
+		}
+		return mappings[i].Environment < mappings[j].Environment
+	})
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	consolidated := len(environment_list) > 0
+	if consolidated {
+		w.Write([]string{"environment", "table", "id", "name", "address"})
+	} else {
+		w.Write([]string{"table", "id", "name", "address"})
+	}
+	renamed := map[string]bool{}
+	for _, m := range mappings {
+		if consolidated {
+			w.Write([]string{m.Environment, m.Table, m.ID, m.Name, m.Address})
+		} else {
+			w.Write([]string{m.Table, m.ID, m.Name, m.Address})
+		}
+		if m.TerraformName != m.Name && !renamed[m.Address] {
+			renamed[m.Address] = true
+			fmt.Printf("renamed %s '%s' to %s\n", m.Table, m.Name, m.Address)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return writeFileAtomic(fmt.Sprintf("%s/terraform_names.csv", output_dir), b.Bytes(), 0644)
+}
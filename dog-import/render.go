@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// expr is a raw Terraform expression such as dog_group.web.id. It is written
+// unquoted in HCL and as an interpolation string in JSON.
+type expr string
+
+// jsonEncoded is a value that the provider expects as a JSON string. It is
+// written as jsonencode({...}) in HCL and as the encoded string in JSON.
+type jsonEncoded struct {
+	value any
+}
+
+type attribute struct {
+	name  string
+	value any
+}
+
+// object is an ordered list of attributes, so that the generated files are
+// stable between runs.
+type object []attribute
+
+type resourceBlock struct {
+	Type  string
+	Name  string
+	Attrs object
+}
+
+type importBlock struct {
+	ID string
+	To string
+}
+
+// tableOutput is everything exported from one table.
+type tableOutput struct {
+	table     string
+	locals    object
+	resources []resourceBlock
+	imports   []importBlock
+	variables []variableBlock
+}
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// newResource builds a resource block, appending the provider meta-argument
+// when one is configured.
+func newResource(resourceType string, name string, attrs object) resourceBlock {
+	if provider_address != "" {
+		attrs = append(attrs, attribute{"provider", expr(provider_address)})
+	}
+	return resourceBlock{Type: resourceType, Name: name, Attrs: attrs}
+}
+
+// toValue converts decoded JSON (vars, fact hosts) into renderable values
+// with map keys sorted.
+func toValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		obj := object{}
+		for _, key := range keys {
+			obj = append(obj, attribute{key, toValue(val[key])})
+		}
+		return obj
+	case []any:
+		list := make([]any, 0, len(val))
+		for _, item := range val {
+			list = append(list, toValue(item))
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+func stringList(values []string) []any {
+	list := make([]any, 0, len(values))
+	for _, value := range values {
+		list = append(list, value)
+	}
+	return list
+}
+
+// hclString quotes s as an HCL string literal, escaping template sequences.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			b.WriteRune(r)
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteRune(r)
+			}
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func hclKey(key string) string {
+	if identifierRe.MatchString(key) {
+		return key
+	}
+	return hclString(key)
+}
+
+func formatNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// hclValue renders a value at the given indent level. Lists and objects are
+// written one element per line.
+func hclValue(v any, indent int) string {
+	pad := strings.Repeat("  ", indent)
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case expr:
+		return string(val)
+	case string:
+		return hclString(val)
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return formatNumber(val)
+	case jsonEncoded:
+		return "jsonencode(" + hclValue(toValue(val.value), indent) + ")"
+	case []any:
+		if len(val) == 0 {
+			return "[]"
+		}
+		if scalarList(val) {
+			items := []string{}
+			for _, item := range val {
+				items = append(items, hclValue(item, indent))
+			}
+			return "[" + strings.Join(items, ", ") + "]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range val {
+			b.WriteString(pad + "  " + hclValue(item, indent+1) + ",\n")
+		}
+		b.WriteString(pad + "]")
+		return b.String()
+	case object:
+		if len(val) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		writeHCLAttributes(&b, val, indent+1)
+		b.WriteString(pad + "}")
+		return b.String()
+	default:
+		return hclString(fmt.Sprint(val))
+	}
+}
+
+func scalarList(list []any) bool {
+	for _, item := range list {
+		switch item.(type) {
+		case object, []any, jsonEncoded:
+			return false
+		}
+	}
+	return true
+}
+
+// writeHCLAttributes writes one attribute per line. Alignment is left to
+// hclwrite.Format, which the rendered files are passed through.
+func writeHCLAttributes(b *strings.Builder, attrs object, indent int) {
+	pad := strings.Repeat("  ", indent)
+	for _, attr := range attrs {
+		fmt.Fprintf(b, "%s%s = %s\n", pad, hclKey(attr.name), hclValue(attr.value, indent))
+	}
+}
+
+// formatHCL formats rendered HCL the way terraform fmt does, so that the
+// generated files don't change the first time it is run on them.
+func formatHCL(b *strings.Builder) []byte {
+	return hclwrite.Format([]byte(b.String()))
+}
+
+func renderHCL(locals object, resources []resourceBlock) []byte {
+	var b strings.Builder
+	if len(locals) > 0 {
+		b.WriteString("locals {\n")
+		writeHCLAttributes(&b, locals, 1)
+		b.WriteString("}\n")
+	}
+	for i, res := range resources {
+		if i > 0 || len(locals) > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "resource %s %s {\n", hclString(res.Type), hclString(res.Name))
+		writeHCLAttributes(&b, res.Attrs, 1)
+		b.WriteString("}\n")
+	}
+	return formatHCL(&b)
+}
+
+func renderHCLImports(imports []importBlock) []byte {
+	var b strings.Builder
+	for _, imp := range imports {
+		b.WriteString("import {\n")
+		fmt.Fprintf(&b, "  id = %s\n", hclString(imp.ID))
+		fmt.Fprintf(&b, "  to = %s\n", imp.To)
+		b.WriteString("}\n")
+	}
+	return formatHCL(&b)
+}
+
+// jsonTemplate escapes literal strings, which Terraform's JSON syntax
+// otherwise treats as templates.
+func jsonTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// jsonValue converts a value into something encoding/json writes in
+// Terraform's JSON configuration syntax.
+func jsonValue(v any) (any, error) {
+	switch val := v.(type) {
+	case expr:
+		return "${" + string(val) + "}", nil
+	case string:
+		return jsonTemplate(val), nil
+	case float64:
+		return json.Number(formatNumber(val)), nil
+	case jsonEncoded:
+		encoded, err := marshalJSON(val.value)
+		if err != nil {
+			return nil, err
+		}
+		return jsonTemplate(string(encoded)), nil
+	case []any:
+		list := make([]any, 0, len(val))
+		for _, item := range val {
+			value, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case object:
+		obj := orderedJSON{}
+		for _, attr := range val {
+			value, err := jsonValue(attr.value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", attr.name, err)
+			}
+			obj = append(obj, attribute{attr.name, value})
+		}
+		return obj, nil
+	default:
+		return v, nil
+	}
+}
+
+// orderedJSON is an object whose keys are marshaled in order.
+type orderedJSON []attribute
+
+func (o orderedJSON) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, attr := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := marshalJSON(attr.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalJSON(attr.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// marshalJSON is json.Marshal without HTML escaping, so that values such as
+// "<none>" stay readable.
+func marshalJSON(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+func indentJSON(v any) ([]byte, error) {
+	encoded, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, encoded, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func renderJSON(locals object, resources []resourceBlock) ([]byte, error) {
+	byType := orderedJSON{}
+	index := map[string]int{}
+	for _, res := range resources {
+		i, ok := index[res.Type]
+		if !ok {
+			i = len(byType)
+			index[res.Type] = i
+			byType = append(byType, attribute{res.Type, orderedJSON{}})
+		}
+		attrs := orderedJSON{}
+		for _, attr := range res.Attrs {
+			if attr.name == "provider" {
+				// meta-arguments take a bare reference, not a template
+				attrs = append(attrs, attribute{attr.name, fmt.Sprint(attr.value)})
+			} else {
+				value, err := jsonValue(attr.value)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s.%s: %w", res.Type, res.Name, attr.name, err)
+				}
+				attrs = append(attrs, attribute{attr.name, value})
+			}
+		}
+		byType[i].value = append(byType[i].value.(orderedJSON), attribute{res.Name, attrs})
+	}
+	if len(locals) == 0 {
+		return indentJSON(orderedJSON{{"resource", byType}})
+	}
+	localValues, err := jsonValue(locals)
+	if err != nil {
+		return nil, fmt.Errorf("locals: %w", err)
+	}
+	return indentJSON(orderedJSON{{"locals", localValues}, {"resource", byType}})
+}
+
+func renderJSONImports(imports []importBlock) ([]byte, error) {
+	list := []any{}
+	for _, imp := range imports {
+		list = append(list, orderedJSON{{"id", imp.ID}, {"to", imp.To}})
+	}
+	return indentJSON(orderedJSON{{"import", list}})
+}
+
+func renderHCLVariables(vars []variableBlock) []byte {
+	var b strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "variable %s {\n", hclString(v.Name))
+		attrs := object{
+			{"description", v.Description},
+			{"type", expr("string")},
+		}
+		if v.Sensitive {
+			attrs = append(attrs, attribute{"sensitive", true})
+		}
+		writeHCLAttributes(&b, attrs, 1)
+		b.WriteString("}\n")
+	}
+	return formatHCL(&b)
+}
+
+func renderJSONVariables(vars []variableBlock) ([]byte, error) {
+	byName := orderedJSON{}
+	for _, v := range vars {
+		// type is a bare type name in JSON, not a template
+		attrs := orderedJSON{
+			{"description", jsonTemplate(v.Description)},
+			{"type", "string"},
+		}
+		if v.Sensitive {
+			attrs = append(attrs, attribute{"sensitive", true})
+		}
+		byName = append(byName, attribute{v.Name, attrs})
+	}
+	return indentJSON(orderedJSON{{"variable", byName}})
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// restorer applies a backup to a dog instance. Objects are matched by name:
+// existing objects are updated, missing ones are created, and the IDs the
+// backup's references use are remapped to the IDs in the target dog.
+type restorer struct {
+	c       *api.Client
+	dryRun  bool
+	backup  *dogSnapshot
+	current *dogSnapshot
+	// ids maps table => ID in the backup => ID in the target dog
+	ids map[string]map[string]string
+
+	created   int
+	updated   int
+	unchanged int
+	failed    int
+}
+
+func runRestore(args []string) int {
+	fs := flag.NewFlagSet("dog-import restore", flag.ExitOnError)
+	file := fs.String("file", "", "archive written by dog-import backup")
+	dryRun := fs.Bool("dry_run", false, "print the changes restore would make without making them")
+	filterFlags(fs)
+	profileFlag(fs)
+	fs.Parse(args)
+	if *file == "" {
+		fmt.Fprintf(os.Stderr, "missing required -file argument/flag\n")
+		return 2
+	}
+	parseFilterFlags()
+	if v := os.Getenv("DOG_READ_ONLY"); v != "" && !*dryRun {
+		readOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "DOG_READ_ONLY must be true or false, got '%s'\n", v)
+			return 2
+		}
+		if readOnly {
+			fmt.Fprintf(os.Stderr, "DOG_READ_ONLY is set, refusing to restore, use -dry_run to see the changes\n")
+			return 1
+		}
+	}
+
+	manifest, backup, err := readBackup(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading %s: %s\n", *file, err)
+		return 1
+	}
+	fmt.Printf("restoring backup of %s taken %s\n", manifest.Endpoint, manifest.Created)
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	current, err := fetchSnapshot(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading dog: %s\n", err)
+		return 1
+	}
+
+	r := newRestorer(c, *dryRun, backup, current)
+	r.run()
+	if *dryRun {
+		fmt.Printf("dry run: %d to create, %d to update, %d unchanged\n", r.created, r.updated, r.unchanged)
+	} else {
+		fmt.Printf("%d created, %d updated, %d unchanged, %d failed\n", r.created, r.updated, r.unchanged, r.failed)
+	}
+	if r.failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func newRestorer(c *api.Client, dryRun bool, backup *dogSnapshot, current *dogSnapshot) *restorer {
+	r := &restorer{c: c, dryRun: dryRun, backup: backup, current: current, ids: map[string]map[string]string{}}
+	backupObjects := snapshotObjects(backup)
+	currentObjects := snapshotObjects(current)
+	for _, table := range all_tables {
+		r.ids[table] = map[string]string{}
+		byName := map[string]string{}
+		for _, obj := range currentObjects[table] {
+			byName[obj.Name] = obj.ID
+		}
+		for _, obj := range backupObjects[table] {
+			if id, ok := byName[obj.Name]; ok {
+				r.ids[table][obj.ID] = id
+			}
+		}
+	}
+	return r
+}
+
+// snapshotObjects returns the ID and name of every object, by table.
+func snapshotObjects(s *dogSnapshot) map[string][]namedObject {
+	objects := map[string][]namedObject{}
+	for _, row := range s.Profiles {
+		objects["profile"] = append(objects["profile"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Services {
+		objects["service"] = append(objects["service"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Zones {
+		objects["zone"] = append(objects["zone"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Rulesets {
+		objects["ruleset"] = append(objects["ruleset"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Groups {
+		objects["group"] = append(objects["group"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Hosts {
+		objects["host"] = append(objects["host"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Links {
+		objects["link"] = append(objects["link"], namedObject{row.ID, row.Name})
+	}
+	for _, row := range s.Facts {
+		objects["fact"] = append(objects["fact"], namedObject{row.ID, row.Name})
+	}
+	return objects
+}
+
+// jsonEqual reports whether two requests would send the same body.
+func jsonEqual(a any, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// apply creates or updates one object. currentID is empty when the target dog
+// has no object of that name, and same is true when updating would not change
+// anything.
+func (r *restorer) apply(table string, name string, backupID string, currentID string, same bool, create func() (string, error), update func() error) {
+	if !slices.Contains(export_tables, table) || !selected(name) {
+		return
+	}
+	switch {
+	case currentID == "":
+		fmt.Printf("create %s '%s'\n", table, name)
+		if r.dryRun {
+			r.ids[table][backupID] = fmt.Sprintf("(new %s %s)", table, name)
+			r.created++
+			return
+		}
+		id, err := create()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create %s '%s': %s\n", table, name, err)
+			r.failed++
+			return
+		}
+		r.ids[table][backupID] = id
+		r.created++
+	case same:
+		r.unchanged++
+	default:
+		fmt.Printf("update %s '%s' (%s)\n", table, name, currentID)
+		if r.dryRun {
+			r.updated++
+			return
+		}
+		if err := update(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to update %s '%s': %s\n", table, name, err)
+			r.failed++
+			return
+		}
+		r.updated++
+	}
+}
+
+// remap returns the target ID of an object referenced by its backup ID.
+// Unknown references are kept, and reported unless they are dog's built in
+// "any" and "all-active" names.
+func (r *restorer) remap(table string, id string, from string) (string, bool) {
+	if newID, ok := r.ids[table][id]; ok {
+		return newID, true
+	}
+	if id != "" && id != "any" && id != "all-active" {
+		fmt.Fprintf(os.Stderr, "warning: %s references %s %s, which is neither in the backup nor in dog\n", from, table, id)
+	}
+	return id, false
+}
+
+func (r *restorer) backupHas(table string, id string) bool {
+	for _, obj := range snapshotObjects(r.backup)[table] {
+		if obj.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+var updateOK = []int{303, 200, 201}
+
+func (r *restorer) run() {
+	currentProfiles := map[string]api.Profile{}
+	for _, row := range r.current.Profiles {
+		currentProfiles[row.Name] = row
+	}
+	for _, row := range r.backup.Profiles {
+		row := row
+		cur := currentProfiles[row.Name]
+		r.apply("profile", row.Name, row.ID, cur.ID, cur.Version == row.Version,
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateProfile(api.ProfileCreateRequest{Name: row.Name, Version: row.Version}, nil)
+				return res.ID, apiStatus("profile", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateProfile(cur.ID, api.ProfileUpdateRequest{Name: row.Name, Version: row.Version}, nil)
+				return apiStatus("profile", statusCode, err, updateOK...)
+			})
+	}
+
+	currentServices := map[string]api.Service{}
+	for _, row := range r.current.Services {
+		currentServices[row.Name] = row
+	}
+	for _, row := range r.backup.Services {
+		row := row
+		cur := currentServices[row.Name]
+		request := api.ServiceUpdateRequest{Services: row.Services, Name: row.Name, Version: row.Version}
+		r.apply("service", row.Name, row.ID, cur.ID, jsonEqual(request, api.ServiceUpdateRequest{Services: cur.Services, Name: cur.Name, Version: cur.Version}),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateService(api.ServiceCreateRequest(request), nil)
+				return res.ID, apiStatus("service", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateService(cur.ID, request, nil)
+				return apiStatus("service", statusCode, err, updateOK...)
+			})
+	}
+
+	currentZones := map[string]api.Zone{}
+	for _, row := range r.current.Zones {
+		currentZones[row.Name] = row
+	}
+	for _, row := range r.backup.Zones {
+		row := row
+		cur := currentZones[row.Name]
+		request := api.ZoneUpdateRequest{IPv4Addresses: row.IPv4Addresses, IPv6Addresses: row.IPv6Addresses, Name: row.Name}
+		r.apply("zone", row.Name, row.ID, cur.ID, jsonEqual(request, api.ZoneUpdateRequest{IPv4Addresses: cur.IPv4Addresses, IPv6Addresses: cur.IPv6Addresses, Name: cur.Name}),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateZone(api.ZoneCreateRequest(request), nil)
+				return res.ID, apiStatus("zone", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateZone(cur.ID, request, nil)
+				return apiStatus("zone", statusCode, err, updateOK...)
+			})
+	}
+
+	// Rules can reference groups, which are restored after rulesets because
+	// groups reference profiles. Rulesets referencing groups that don't exist
+	// yet are updated again once the groups have been created.
+	currentRulesets := map[string]api.Ruleset{}
+	for _, row := range r.current.Rulesets {
+		currentRulesets[row.Name] = row
+	}
+	pending := []api.Ruleset{}
+	for _, row := range r.backup.Rulesets {
+		row := row
+		request, complete := r.rulesetRequest(row)
+		if !complete {
+			pending = append(pending, row)
+		}
+		cur := currentRulesets[row.Name]
+		r.apply("ruleset", row.Name, row.ID, cur.ID, jsonEqual(request, api.RulesetUpdateRequest{Name: cur.Name, Rules: cur.Rules, ProfileId: cur.ProfileId}),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateRuleset(api.RulesetCreateRequest(request), nil)
+				return res.ID, apiStatus("ruleset", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateRuleset(cur.ID, request, nil)
+				return apiStatus("ruleset", statusCode, err, updateOK...)
+			})
+	}
+
+	currentGroups := map[string]api.GroupJson{}
+	for _, row := range r.current.Groups {
+		currentGroups[row.Name] = row
+	}
+	for _, row := range r.backup.Groups {
+		if row.ID == "all-active" {
+			continue
+		}
+		row := row
+		cur := currentGroups[row.Name]
+		request := row
+		request.ID = ""
+		if request.ProfileId != "" {
+			request.ProfileId, _ = r.remap("profile", row.ProfileId, "group "+row.Name)
+		}
+		compare := cur
+		compare.ID = ""
+		r.apply("group", row.Name, row.ID, cur.ID, jsonEqual(request, compare),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateGroup(request, nil)
+				return res.ID, apiStatus("group", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateGroup(cur.ID, request, nil)
+				return apiStatus("group", statusCode, err, updateOK...)
+			})
+	}
+
+	for _, row := range pending {
+		rulesetID, ok := r.ids["ruleset"][row.ID]
+		if !ok || !slices.Contains(export_tables, "ruleset") || !selected(row.Name) {
+			continue
+		}
+		request, _ := r.rulesetRequest(row)
+		fmt.Printf("update ruleset '%s' with the IDs of restored groups\n", row.Name)
+		if r.dryRun {
+			continue
+		}
+		_, statusCode, err := r.c.UpdateRuleset(rulesetID, request, nil)
+		if err := apiStatus("ruleset", statusCode, err, updateOK...); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to update ruleset '%s': %s\n", row.Name, err)
+			r.failed++
+		}
+	}
+
+	currentHosts := map[string]api.HostJson{}
+	for _, row := range r.current.Hosts {
+		currentHosts[row.Name] = row
+	}
+	for _, row := range r.backup.Hosts {
+		row := row
+		cur := currentHosts[row.Name]
+		request := row
+		request.ID = ""
+		compare := cur
+		compare.ID = ""
+		r.apply("host", row.Name, row.ID, cur.ID, jsonEqual(request, compare),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateHost(request, nil)
+				return res.ID, apiStatus("host", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateHost(cur.ID, request, nil)
+				return apiStatus("host", statusCode, err, updateOK...)
+			})
+	}
+
+	currentLinks := map[string]api.Link{}
+	for _, row := range r.current.Links {
+		currentLinks[row.Name] = row
+	}
+	for _, row := range r.backup.Links {
+		row := row
+		cur := currentLinks[row.Name]
+		request := api.LinkUpdateRequest{
+			AddressHandling: row.AddressHandling,
+			Connection:      row.Connection,
+			ConnectionType:  row.ConnectionType,
+			Direction:       row.Direction,
+			Enabled:         row.Enabled,
+			Name:            row.Name,
+		}
+		compare := api.LinkUpdateRequest{
+			AddressHandling: cur.AddressHandling,
+			Connection:      cur.Connection,
+			ConnectionType:  cur.ConnectionType,
+			Direction:       cur.Direction,
+			Enabled:         cur.Enabled,
+			Name:            cur.Name,
+		}
+		r.apply("link", row.Name, row.ID, cur.ID, jsonEqual(request, compare),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateLink(api.LinkCreateRequest(request), nil)
+				return res.ID, apiStatus("link", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateLink(cur.ID, request, nil)
+				return apiStatus("link", statusCode, err, updateOK...)
+			})
+	}
+
+	currentFacts := map[string]api.FactJson{}
+	for _, row := range r.current.Facts {
+		currentFacts[row.Name] = row
+	}
+	for _, row := range r.backup.Facts {
+		row := row
+		cur := currentFacts[row.Name]
+		request := row
+		request.ID = ""
+		compare := cur
+		compare.ID = ""
+		r.apply("fact", row.Name, row.ID, cur.ID, jsonEqual(request, compare),
+			func() (string, error) {
+				res, statusCode, err := r.c.CreateFact(request, nil)
+				return res.ID, apiStatus("fact", statusCode, err, 201)
+			},
+			func() error {
+				_, statusCode, err := r.c.UpdateFact(cur.ID, request, nil)
+				return apiStatus("fact", statusCode, err, updateOK...)
+			})
+	}
+}
+
+// rulesetRequest copies a ruleset from the backup with its profile, zone,
+// group and service IDs remapped. complete is false when a rule references a
+// group from the backup that doesn't exist in the target dog yet.
+func (r *restorer) rulesetRequest(row api.Ruleset) (request api.RulesetUpdateRequest, complete bool) {
+	complete = true
+	from := "ruleset " + row.Name
+	request = api.RulesetUpdateRequest{Name: row.Name}
+	if row.ProfileId != nil {
+		profileID, _ := r.remap("profile", *row.ProfileId, from)
+		request.ProfileId = &profileID
+	}
+	if row.Rules == nil {
+		return request, complete
+	}
+	remapRules := func(rules []*api.Rule) []*api.Rule {
+		out := make([]*api.Rule, 0, len(rules))
+		for _, rule := range rules {
+			copied := *rule
+			switch copied.GroupType {
+			case "ZONE":
+				copied.Group, _ = r.remap("zone", rule.Group, from)
+			case "ROLE":
+				if _, ok := r.ids["group"][rule.Group]; !ok && r.backupHas("group", rule.Group) {
+					// restored later, see pending in run
+					complete = false
+				} else {
+					copied.Group, _ = r.remap("group", rule.Group, from)
+				}
+			}
+			if copied.Service != "any" {
+				copied.Service, _ = r.remap("service", rule.Service, from)
+			}
+			out = append(out, &copied)
+		}
+		return out
+	}
+	request.Rules = &api.Rules{
+		Inbound:  remapRules(row.Rules.Inbound),
+		Outbound: remapRules(row.Rules.Outbound),
+	}
+	return request, complete
+}
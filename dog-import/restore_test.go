@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// fakeDog is a fake dog API that keeps its objects in memory, so restore can
+// create and update them. Lists are served from /<table>s, objects are
+// created with POST /<table> and updated with PUT /<table>/<id>.
+type fakeDog struct {
+	mu     sync.Mutex
+	tables map[string][]map[string]any
+	writes int
+	nextID int
+}
+
+func newFakeDog(t *testing.T, tables map[string][]map[string]any) (*fakeDog, *httptest.Server) {
+	t.Helper()
+	d := &fakeDog{tables: map[string][]map[string]any{}}
+	for _, table := range all_tables {
+		d.tables[table] = append([]map[string]any{}, tables[table]...)
+	}
+	server := httptest.NewServer(http.HandlerFunc(d.serve))
+	t.Cleanup(server.Close)
+	return d, server
+}
+
+func (d *fakeDog) serve(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	table, id, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+	var body map[string]any
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.writes++
+	}
+	switch {
+	case r.Method == http.MethodGet && id == "" && strings.HasSuffix(table, "s"):
+		writeJSON(w, http.StatusOK, d.tables[strings.TrimSuffix(table, "s")])
+	case r.Method == http.MethodPost && id == "":
+		d.nextID++
+		body["id"] = fmt.Sprintf("new-%d", d.nextID)
+		d.tables[table] = append(d.tables[table], body)
+		writeJSON(w, http.StatusCreated, body)
+	case r.Method == http.MethodPut && id != "":
+		for i, row := range d.tables[table] {
+			if row["id"] == id {
+				body["id"] = id
+				d.tables[table][i] = body
+				writeJSON(w, http.StatusOK, body)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (d *fakeDog) find(table string, name string) map[string]any {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, row := range d.tables[table] {
+		if row["name"] == name {
+			return row
+		}
+	}
+	return nil
+}
+
+// backupFakeAPI writes a backup of the fake API's fixtures and reads it back
+// the way restore does.
+func backupFakeAPI(t *testing.T) (string, *dogSnapshot) {
+	t.Helper()
+	server := fakeAPI(t)
+	snapshot, err := fetchSnapshot(api.NewClient("token", server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "dog.tar.gz")
+	if err := writeBackup(path, server.URL, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	_, backup, err := readBackup(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, backup
+}
+
+// restoreTarget is a dog that already has some of the backup's objects under
+// other IDs: the web profile and the office zone as they are in the backup,
+// and the ssh service with another port.
+func restoreTarget() map[string][]map[string]any {
+	return map[string][]map[string]any{
+		"profile": {{"id": "t-p1", "name": "web", "version": "1.0"}},
+		"service": {{"id": "t-s1", "name": "ssh-tcp-22", "version": 1, "services": []any{
+			map[string]any{"protocol": "tcp", "ports": []any{"2222"}},
+		}}},
+		"zone": {{"id": "t-z1", "name": "office", "ipv4_addresses": []any{"10.0.0.0/8"}, "ipv6_addresses": []any{}}},
+	}
+}
+
+func TestRestoreRoundTrip(t *testing.T) {
+	export_tables = all_tables
+	include_re, exclude_re = nil, nil
+	_, backup := backupFakeAPI(t)
+	target, server := newFakeDog(t, restoreTarget())
+	c := api.NewClient("token", server.URL)
+
+	restore := func(dryRun bool) *restorer {
+		t.Helper()
+		current, err := fetchSnapshot(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := newRestorer(c, dryRun, backup, current)
+		r.run()
+		if r.failed != 0 {
+			t.Fatalf("%d failed", r.failed)
+		}
+		return r
+	}
+
+	// 2 profiles, 1 service, 2 zones, 2 rulesets, 2 groups (all-active is
+	// built in), 3 hosts, 1 link and 1 fact
+	r := restore(true)
+	if r.created != 11 || r.updated != 1 || r.unchanged != 2 {
+		t.Errorf("dry run: got %d to create, %d to update, %d unchanged, want 11, 1, 2", r.created, r.updated, r.unchanged)
+	}
+	if target.writes != 0 {
+		t.Fatalf("dry run made %d writes", target.writes)
+	}
+
+	r = restore(false)
+	if r.created != 11 || r.updated != 1 || r.unchanged != 2 {
+		t.Errorf("got %d created, %d updated, %d unchanged, want 11, 1, 2", r.created, r.updated, r.unchanged)
+	}
+
+	if ports := target.find("service", "ssh-tcp-22")["services"]; fmt.Sprint(ports) != "[map[ports:[22] protocol:tcp]]" {
+		t.Errorf("service ssh-tcp-22 was not updated: %v", ports)
+	}
+	// the rules of the web ruleset reference the office zone, the ssh
+	// service and the web_prod group by their IDs in the target dog
+	ruleset := target.find("ruleset", "web")
+	if ruleset == nil {
+		t.Fatal("ruleset web was not created")
+	}
+	if ruleset["profile_id"] != "t-p1" {
+		t.Errorf("ruleset web has profile %v, want t-p1", ruleset["profile_id"])
+	}
+	group := target.find("group", "web_prod")
+	if group == nil {
+		t.Fatal("group web_prod was not created")
+	}
+	inbound := ruleset["rules"].(map[string]any)["inbound"].([]any)
+	for i, want := range [][2]any{{"t-z1", "t-s1"}, {group["id"], "any"}, {"any", "any"}} {
+		rule := inbound[i].(map[string]any)
+		if rule["group"] != want[0] || rule["service"] != want[1] {
+			t.Errorf("inbound rule %d: got group %v service %v, want group %v service %v", i, rule["group"], rule["service"], want[0], want[1])
+		}
+	}
+	if profile := target.find("profile", "db"); group["profile_id"] != profile["id"] {
+		t.Errorf("group web_prod has profile %v, want %v", group["profile_id"], profile["id"])
+	}
+
+	// restoring the same backup again has nothing left to do
+	r = restore(true)
+	if r.created != 0 || r.updated != 0 || r.unchanged != 14 {
+		t.Errorf("second restore: got %d to create, %d to update, %d unchanged, want 0, 0, 14", r.created, r.updated, r.unchanged)
+	}
+}
+
+func TestRestoreReadOnly(t *testing.T) {
+	path, _ := backupFakeAPI(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("restore contacted dog: %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("DOG_API_ENDPOINT", server.URL)
+	t.Setenv("DOG_PROFILE", "")
+	t.Setenv("DOG_READ_ONLY", "true")
+	if status := runRestore([]string{"-file", path}); status != 1 {
+		t.Errorf("got exit status %d, want 1", status)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// variableBlock is a Terraform input variable of type string.
+type variableBlock struct {
+	Name        string
+	Description string
+	Sensitive   bool
+}
+
+var (
+	secretsMu    sync.Mutex
+	secretValues = map[string]string{}
+)
+
+// secret returns the value to write for a sensitive attribute. Unless
+// -plaintext_secrets is set, non-empty values are replaced with a reference
+// to a sensitive variable, and the value is kept for the -secret_vars file.
+func secret(vars *[]variableBlock, table string, terraformName string, attr string, description string, value string) any {
+	if plaintext_secrets || value == "" {
+		return value
+	}
+	name := fmt.Sprintf("dog_%s_%s_%s", table, terraformName, attr)
+	*vars = append(*vars, variableBlock{Name: name, Description: description, Sensitive: true})
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secretValues[name] = value
+	return expr("var." + name)
+}
+
+// writeVariables writes the variable blocks of a table to
+// <table>_variables.tf (or .tf.json).
+func writeVariables(output_dir string, table string, vars []variableBlock) error {
+	if len(vars) == 0 {
+		return nil
+	}
+	if format == "json" {
+		content, err := renderJSONVariables(vars)
+		if err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		return writeFileAtomic(fmt.Sprintf("%s/%s_variables.tf.json", output_dir, table), content, 0644)
+	}
+	return writeFileAtomic(fmt.Sprintf("%s/%s_variables.tf", output_dir, table), renderHCLVariables(vars), 0644)
+}
+
+// writeSecretValues writes the values of the generated variables to path as a
+// tfvars file readable only by the current user. Paths ending in .json are
+// written in JSON syntax.
+func writeSecretValues(path string) error {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	names := make([]string, 0, len(secretValues))
+	for name := range secretValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var content []byte
+	if strings.HasSuffix(path, ".json") {
+		values := orderedJSON{}
+		for _, name := range names {
+			values = append(values, attribute{name, secretValues[name]})
+		}
+		var err error
+		if content, err = indentJSON(values); err != nil {
+			return err
+		}
+	} else {
+		attrs := object{}
+		for _, name := range names {
+			attrs = append(attrs, attribute{name, secretValues[name]})
+		}
+		var b strings.Builder
+		writeHCLAttributes(&b, attrs, 0)
+		content = formatHCL(&b)
+	}
+	// the temporary file is created 0600 before anything is written to it
+	return writeFileAtomic(path, content, 0600)
+}
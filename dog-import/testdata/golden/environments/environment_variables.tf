@@ -0,0 +1,4 @@
+variable "environment" {
+  description = "dog environment of this instance of the module, one of: qa, prod"
+  type        = string
+}
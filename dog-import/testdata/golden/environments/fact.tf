@@ -0,0 +1,16 @@
+resource "dog_fact" "qa" {
+  name = "qa"
+  groups = {
+    all = {
+      children = ["web"]
+      hosts = jsonencode({
+        h = {
+          k = "v"
+        }
+      })
+      vars = jsonencode({
+        a = 1
+      })
+    }
+  }
+}
@@ -0,0 +1,8 @@
+import {
+  id = "f1"
+  to = module.dog_qa.dog_fact.qa
+}
+import {
+  id = "f1"
+  to = module.dog_prod.dog_fact.qa
+}
@@ -0,0 +1,29 @@
+resource "dog_group" "web_prod_2" {
+  description     = "web tier"
+  name            = "web.prod"
+  profile_name    = dog_profile.web.name
+  profile_id      = dog_profile.web.id
+  profile_version = "v1"
+  ec2_security_group_ids = [
+    {
+      region = "us-east-1"
+      sgid   = "sg-1234"
+    },
+  ]
+  alert_enable = true
+  vars = jsonencode({
+    debug = false
+    owner = "ops"
+    port  = 8080
+    tags  = ["a", "b"]
+  })
+}
+
+resource "dog_group" "web_prod" {
+  description            = ""
+  name                   = "web_prod"
+  profile_name           = dog_profile.db.name
+  profile_id             = dog_profile.db.id
+  profile_version        = "latest"
+  ec2_security_group_ids = []
+}
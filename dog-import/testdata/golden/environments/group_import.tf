@@ -0,0 +1,16 @@
+import {
+  id = "g1"
+  to = module.dog_qa.dog_group.web_prod_2
+}
+import {
+  id = "g2"
+  to = module.dog_qa.dog_group.web_prod
+}
+import {
+  id = "g1"
+  to = module.dog_prod.dog_group.web_prod_2
+}
+import {
+  id = "g2"
+  to = module.dog_prod.dog_group.web_prod
+}
@@ -0,0 +1,27 @@
+resource "dog_host" "qa-web-1" {
+  environment  = "qa"
+  group        = dog_group.web_prod_2.name
+  hostkey      = "k1"
+  location     = "us"
+  name         = "qa-web-1"
+  alert_enable = false
+  vars = jsonencode({
+    x = "y"
+  })
+}
+
+resource "dog_host" "prod-db-1" {
+  environment = "prod"
+  group       = dog_group.web_prod.name
+  hostkey     = "k2"
+  location    = "us"
+  name        = "prod-db-1"
+}
+
+resource "dog_host" "qa-old-1" {
+  environment = "qa"
+  group       = "retired"
+  hostkey     = "k3"
+  location    = "us"
+  name        = "qa-old-1"
+}
@@ -0,0 +1,24 @@
+import {
+  id = "h1"
+  to = module.dog_qa.dog_host.qa-web-1
+}
+import {
+  id = "h2"
+  to = module.dog_qa.dog_host.prod-db-1
+}
+import {
+  id = "h3"
+  to = module.dog_qa.dog_host.qa-old-1
+}
+import {
+  id = "h1"
+  to = module.dog_prod.dog_host.qa-web-1
+}
+import {
+  id = "h2"
+  to = module.dog_prod.dog_host.prod-db-1
+}
+import {
+  id = "h3"
+  to = module.dog_prod.dog_host.qa-old-1
+}
@@ -0,0 +1,23 @@
+resource "dog_link" "q1" {
+  address_handling = "union"
+  dog_connection = {
+    api_port = 15672
+    host     = "broker"
+    password = var.dog_link_q1_password
+    port     = 5673
+    ssl_options = {
+      cacertfile             = var.dog_link_q1_cacertfile
+      certfile               = var.dog_link_q1_certfile
+      fail_if_no_peer_cert   = true
+      keyfile                = var.dog_link_q1_keyfile
+      server_name_indication = "disable"
+      verify                 = "verify_peer"
+    }
+    user         = "dog"
+    virtual_host = "dog"
+  }
+  connection_type = "thumper"
+  direction       = "bidirectional"
+  enabled         = false
+  name            = "q1"
+}
@@ -0,0 +1,8 @@
+import {
+  id = "l1"
+  to = module.dog_qa.dog_link.q1
+}
+import {
+  id = "l1"
+  to = module.dog_prod.dog_link.q1
+}
@@ -0,0 +1,23 @@
+variable "dog_link_q1_cacertfile" {
+  description = "CA certificate file of dog link q1"
+  type        = string
+  sensitive   = true
+}
+
+variable "dog_link_q1_certfile" {
+  description = "certificate file of dog link q1"
+  type        = string
+  sensitive   = true
+}
+
+variable "dog_link_q1_keyfile" {
+  description = "key file of dog link q1"
+  type        = string
+  sensitive   = true
+}
+
+variable "dog_link_q1_password" {
+  description = "password of dog link q1"
+  type        = string
+  sensitive   = true
+}
@@ -0,0 +1,9 @@
+resource "dog_profile" "web" {
+  name    = "web"
+  version = "1.0"
+}
+
+resource "dog_profile" "db" {
+  name    = "db"
+  version = "1.0"
+}
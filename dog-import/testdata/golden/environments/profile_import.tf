@@ -0,0 +1,16 @@
+import {
+  id = "p1"
+  to = module.dog_qa.dog_profile.web
+}
+import {
+  id = "p2"
+  to = module.dog_qa.dog_profile.db
+}
+import {
+  id = "p1"
+  to = module.dog_prod.dog_profile.web
+}
+import {
+  id = "p2"
+  to = module.dog_prod.dog_profile.db
+}
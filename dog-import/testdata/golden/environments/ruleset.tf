@@ -0,0 +1,90 @@
+resource "dog_ruleset" "web" {
+  name       = "web"
+  profile_id = dog_profile.web.id
+  rules = {
+    inbound = [
+      {
+        action       = "ACCEPT"
+        active       = true
+        comment      = "ssh"
+        environments = []
+        group        = dog_zone.office[var.environment].id
+        group_type   = "ZONE"
+        interface    = ""
+        log          = false
+        log_prefix   = ""
+        service      = dog_service.ssh-tcp-22.id
+        states       = []
+        type         = "BASIC"
+      },
+      {
+        action       = "ACCEPT"
+        active       = true
+        comment      = ""
+        environments = []
+        group        = dog_group.web_prod.id
+        group_type   = "ROLE"
+        interface    = ""
+        log          = false
+        log_prefix   = ""
+        service      = "any"
+        states       = []
+        type         = "BASIC"
+      },
+      {
+        action       = "DROP"
+        active       = true
+        comment      = ""
+        environments = []
+        group        = "any"
+        group_type   = "ANY"
+        interface    = ""
+        log          = false
+        log_prefix   = ""
+        service      = "any"
+        states       = []
+        type         = "BASIC"
+      },
+    ]
+    outbound = [
+      {
+        action       = "ACCEPT"
+        active       = true
+        comment      = ""
+        environments = []
+        group        = "any"
+        group_type   = "ANY"
+        interface    = ""
+        log          = false
+        log_prefix   = ""
+        service      = "any"
+        states       = []
+        type         = "BASIC"
+      },
+    ]
+  }
+}
+
+resource "dog_ruleset" "db-rules" {
+  name       = "db-rules"
+  profile_id = dog_profile.db.id
+  rules = {
+    inbound = [
+      {
+        action       = "ACCEPT"
+        active       = true
+        comment      = "retired zone"
+        environments = []
+        group        = "z404"
+        group_type   = "ZONE"
+        interface    = ""
+        log          = false
+        log_prefix   = ""
+        service      = dog_service.ssh-tcp-22.id
+        states       = []
+        type         = "BASIC"
+      },
+    ]
+    outbound = []
+  }
+}
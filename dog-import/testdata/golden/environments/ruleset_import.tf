@@ -0,0 +1,16 @@
+import {
+  id = "r1"
+  to = module.dog_qa.dog_ruleset.web
+}
+import {
+  id = "r2"
+  to = module.dog_qa.dog_ruleset.db-rules
+}
+import {
+  id = "r1"
+  to = module.dog_prod.dog_ruleset.web
+}
+import {
+  id = "r2"
+  to = module.dog_prod.dog_ruleset.db-rules
+}
@@ -0,0 +1,10 @@
+resource "dog_service" "ssh-tcp-22" {
+  name    = "ssh-tcp-22"
+  version = "1"
+  services = [
+    {
+      protocol = "tcp"
+      ports    = ["22"]
+    },
+  ]
+}
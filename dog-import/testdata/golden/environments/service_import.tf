@@ -0,0 +1,8 @@
+import {
+  id = "s1"
+  to = module.dog_qa.dog_service.ssh-tcp-22
+}
+import {
+  id = "s1"
+  to = module.dog_prod.dog_service.ssh-tcp-22
+}
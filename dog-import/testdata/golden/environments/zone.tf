@@ -0,0 +1,23 @@
+locals {
+  zone_office = {
+    qa = {
+      ipv4_addresses = ["10.0.0.0/8"]
+    }
+    prod = {
+      ipv4_addresses = ["10.1.0.0/16"]
+    }
+  }
+}
+
+resource "dog_zone" "office" {
+  for_each       = { for env, values in local.zone_office : env => values if env == var.environment }
+  name           = "office"
+  ipv4_addresses = each.value.ipv4_addresses
+  ipv6_addresses = []
+}
+
+resource "dog_zone" "_1st_zone" {
+  name           = "1st zone"
+  ipv4_addresses = []
+  ipv6_addresses = []
+}
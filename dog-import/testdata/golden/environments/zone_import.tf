@@ -0,0 +1,16 @@
+import {
+  id = "z1"
+  to = module.dog_qa.dog_zone.office["qa"]
+}
+import {
+  id = "z2"
+  to = module.dog_qa.dog_zone._1st_zone
+}
+import {
+  id = "z1"
+  to = module.dog_prod.dog_zone.office["prod"]
+}
+import {
+  id = "z2"
+  to = module.dog_prod.dog_zone._1st_zone
+}
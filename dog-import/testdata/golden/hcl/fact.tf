@@ -0,0 +1,17 @@
+resource "dog_fact" "qa" {
+  name = "qa"
+  groups = {
+    all = {
+      children = ["web"]
+      hosts = jsonencode({
+        h = {
+          k = "v"
+        }
+      })
+      vars = jsonencode({
+        a = 1
+      })
+    }
+  }
+  provider = dog.qa
+}
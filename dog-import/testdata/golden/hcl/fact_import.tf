@@ -0,0 +1,4 @@
+import {
+  id = "f1"
+  to = module.dog.dog_fact.qa
+}
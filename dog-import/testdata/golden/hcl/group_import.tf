@@ -0,0 +1,8 @@
+import {
+  id = "g1"
+  to = module.dog.dog_group.web_prod_2
+}
+import {
+  id = "g2"
+  to = module.dog.dog_group.web_prod
+}
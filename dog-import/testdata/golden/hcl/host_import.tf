@@ -0,0 +1,12 @@
+import {
+  id = "h1"
+  to = module.dog.dog_host.qa-web-1
+}
+import {
+  id = "h2"
+  to = module.dog.dog_host.prod-db-1
+}
+import {
+  id = "h3"
+  to = module.dog.dog_host.qa-old-1
+}
@@ -0,0 +1,4 @@
+import {
+  id = "l1"
+  to = module.dog.dog_link.q1
+}
@@ -0,0 +1,11 @@
+resource "dog_profile" "web" {
+  name     = "web"
+  version  = "1.0"
+  provider = dog.qa
+}
+
+resource "dog_profile" "db" {
+  name     = "db"
+  version  = "1.0"
+  provider = dog.qa
+}
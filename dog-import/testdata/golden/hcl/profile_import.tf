@@ -0,0 +1,8 @@
+import {
+  id = "p1"
+  to = module.dog.dog_profile.web
+}
+import {
+  id = "p2"
+  to = module.dog.dog_profile.db
+}
@@ -0,0 +1,8 @@
+import {
+  id = "r1"
+  to = module.dog.dog_ruleset.web
+}
+import {
+  id = "r2"
+  to = module.dog.dog_ruleset.db-rules
+}
@@ -0,0 +1,4 @@
+import {
+  id = "s1"
+  to = module.dog.dog_service.ssh-tcp-22
+}
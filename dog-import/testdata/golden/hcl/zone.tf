@@ -0,0 +1,13 @@
+resource "dog_zone" "office" {
+  name           = "office"
+  ipv4_addresses = ["10.0.0.0/8"]
+  ipv6_addresses = []
+  provider       = dog.qa
+}
+
+resource "dog_zone" "_1st_zone" {
+  name           = "1st zone"
+  ipv4_addresses = []
+  ipv6_addresses = []
+  provider       = dog.qa
+}
@@ -0,0 +1,8 @@
+import {
+  id = "z1"
+  to = module.dog.dog_zone.office
+}
+import {
+  id = "z2"
+  to = module.dog.dog_zone._1st_zone
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/relaypro-open/dog_api_golang/api"
+)
+
+// defaultTokenCommandTimeout is how long DOG_API_TOKEN_COMMAND may run when
+// no timeout is given, the same as the provider's api_token_command.
+const defaultTokenCommandTimeout = 30 * time.Second
+
+// tokenSource is where the API token is read from, looked up the same way as
+// the provider does from its environment variables: the first of the token,
+// file and command that is set is used. This file is a copy of
+// internal/provider/token.go and must be kept in sync with it, apart from the
+// per-environment variables and the commands below apiToken.
+type tokenSource struct {
+	token   string
+	file    string
+	command string
+	timeout time.Duration
+}
+
+// envTokenSource reads the token source from DOG_API_TOKEN,
+// DOG_API_TOKEN_FILE, DOG_API_TOKEN_COMMAND and
+// DOG_API_TOKEN_COMMAND_TIMEOUT, or from their per-environment variants,
+// such as DOG_API_TOKEN_FILE_QA, when env isn't empty.
+func envTokenSource(env string) (tokenSource, error) {
+	name := func(name string) string {
+		if env == "" {
+			return name
+		}
+		return environmentVariable(name, env)
+	}
+	s := tokenSource{
+		token:   os.Getenv(name("DOG_API_TOKEN")),
+		file:    os.Getenv(name("DOG_API_TOKEN_FILE")),
+		command: os.Getenv(name("DOG_API_TOKEN_COMMAND")),
+		timeout: defaultTokenCommandTimeout,
+	}
+	if v := os.Getenv(name("DOG_API_TOKEN_COMMAND_TIMEOUT")); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return s, fmt.Errorf("%s must be a duration such as 30s, got: %q", name("DOG_API_TOKEN_COMMAND_TIMEOUT"), v)
+		}
+		s.timeout = timeout
+	}
+	return s, nil
+}
+
+// resolve returns the token, or "" when no source is set.
+func (s tokenSource) resolve() (string, error) {
+	switch {
+	case s.token != "":
+		return s.token, nil
+	case s.file != "":
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return "", err
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("%s is empty", s.file)
+		}
+		return token, nil
+	case s.command != "":
+		return runTokenCommand(s.command, s.timeout)
+	default:
+		return "", nil
+	}
+}
+
+// runTokenCommand runs command with the system shell and returns its
+// standard output. Only standard error is included in errors, since the
+// output may hold part of the token.
+func runTokenCommand(command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%q did not finish within %s", command, timeout)
+		}
+		return "", fmt.Errorf("%q failed: %s: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("%q printed no token", command)
+	}
+	return token, nil
+}
+
+// apiToken returns the token of env, or of DOG_API_TOKEN and friends when env
+// is "".
+func apiToken(env string) (string, error) {
+	s, err := envTokenSource(env)
+	if err != nil {
+		return "", err
+	}
+	return s.resolve()
+}
+
+// profile_name is the connection profile selected with -profile or
+// DOG_PROFILE.
+var profile_name string
+
+// profileFlag adds -profile to the flags of a command.
+func profileFlag(fs *flag.FlagSet) {
+	fs.StringVar(&profile_name, "profile", os.Getenv("DOG_PROFILE"), "connection profile in ~/.config/dog/config.yaml, defaults to DOG_PROFILE")
+}
+
+// newClient returns a client for DOG_API_ENDPOINT with the token read from
+// the environment, both overridden by the selected profile, the same way as
+// the provider does.
+func newClient() (*api.Client, error) {
+	s, err := envTokenSource("")
+	if err != nil {
+		return nil, fmt.Errorf("reading the dog API token: %w", err)
+	}
+	endpoint := os.Getenv("DOG_API_ENDPOINT")
+	var profile connectionProfile
+	if profile_name != "" {
+		if profile, err = loadConnectionProfile(profile_name); err != nil {
+			return nil, fmt.Errorf("reading the dog profile '%s': %w", profile_name, err)
+		}
+		if profile.API_Endpoint != "" {
+			endpoint = profile.API_Endpoint
+		}
+		if err := profile.applyTokenSource(&s); err != nil {
+			return nil, fmt.Errorf("reading the dog profile '%s': %w", profile_name, err)
+		}
+	}
+	token, err := s.resolve()
+	if err != nil {
+		return nil, fmt.Errorf("reading the dog API token: %w", err)
+	}
+	c := api.NewClient(token, endpoint)
+	if err := profile.applyTLS(c); err != nil {
+		return nil, fmt.Errorf("using the TLS settings of the dog profile '%s': %w", profile_name, err)
+	}
+	return c, nil
+}
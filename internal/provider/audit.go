@@ -0,0 +1,107 @@
+package dog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// auditRecord is one line of the audit log.
+type auditRecord struct {
+	Time         string `json:"time"`
+	RunID        string `json:"run_id"`
+	User         string `json:"user,omitempty"`
+	ResourceType string `json:"resource_type"`
+	Operation    string `json:"operation"`
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	StatusCode   int    `json:"status_code"`
+	Request      any    `json:"request,omitempty"`
+}
+
+// auditLog appends a JSON line to a local file for every change made to dog.
+type auditLog struct {
+	path  string
+	runID string
+	user  string
+
+	mu sync.Mutex
+}
+
+// newAuditLog opens the audit log at path. The run ID is taken from
+// TFC_RUN_ID when Terraform runs in HCP Terraform, otherwise one is generated
+// for this provider process.
+func newAuditLog(path string) (*auditLog, error) {
+	runID := os.Getenv("TFC_RUN_ID")
+	if runID == "" {
+		var err error
+		if runID, err = uuid.GenerateUUID(); err != nil {
+			return nil, err
+		}
+	}
+	a := &auditLog{path: path, runID: runID}
+	if u, err := user.Current(); err == nil {
+		a.user = u.Username
+	}
+	// fail during Configure, rather than after the first change, when the
+	// file can't be written
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return a, f.Close()
+}
+
+func (a *auditLog) write(record auditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// audit records a create, update or delete sent to dog, whatever its outcome,
+// when the provider has an audit_log_path. The change has already been made,
+// so a failure to write the record is a warning.
+func (p *dogProvider) audit(diags *diag.Diagnostics, resourceType string, operation string, id string, name string, statusCode int, request any) {
+	if p.auditLog == nil {
+		return
+	}
+	record := auditRecord{
+		Time:         time.Now().UTC().Format(time.RFC3339Nano),
+		RunID:        p.auditLog.runID,
+		User:         p.auditLog.user,
+		ResourceType: resourceType,
+		Operation:    operation,
+		ID:           id,
+		Name:         name,
+		StatusCode:   statusCode,
+	}
+	if request != nil {
+		redacted, err := redact(request)
+		if err != nil {
+			diags.AddWarning("Audit Log", fmt.Sprintf("Unable to redact the %s %s request for %s: %s", operation, resourceType, p.auditLog.path, err))
+			return
+		}
+		record.Request = redacted
+	}
+	if err := p.auditLog.write(record); err != nil {
+		diags.AddWarning("Audit Log", fmt.Sprintf("Unable to write the %s %s record to %s: %s", operation, resourceType, p.auditLog.path, err))
+	}
+}
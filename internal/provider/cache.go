@@ -0,0 +1,155 @@
+package dog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// dogTypes maps the path segment used to read or change a single object of
+// each dog type to the segment that lists them.
+var dogTypes = map[string]string{
+	"fact":    "facts",
+	"group":   "groups",
+	"host":    "hosts",
+	"link":    "links",
+	"profile": "profiles",
+	"ruleset": "rulesets",
+	"service": "services",
+	"zone":    "zones",
+}
+
+type cachedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// listCache is an http.RoundTripper that keeps the responses of list calls,
+// such as GET /hosts, for the life of the provider. Concurrent reads of the
+// same list share one request, and any request that changes an object drops
+// the cached lists of its type.
+type listCache struct {
+	next  http.RoundTripper
+	group singleflight.Group
+
+	mu        sync.Mutex
+	responses map[string]map[string]cachedResponse
+	// generations counts the changes to each type, so that a list read
+	// while an object was changed isn't cached
+	generations map[string]int
+}
+
+func newListCache(next http.RoundTripper) *listCache {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &listCache{
+		next:        next,
+		responses:   map[string]map[string]cachedResponse{},
+		generations: map[string]int{},
+	}
+}
+
+// listType returns the type listed by a request path such as /api/V2/hosts,
+// or "" if the path doesn't list a type.
+func listType(path string) string {
+	last := path[strings.LastIndex(path, "/")+1:]
+	for _, plural := range dogTypes {
+		if last == plural {
+			return plural
+		}
+	}
+	return ""
+}
+
+// changedType returns the list a request path such as /api/V2/host/1234
+// belongs to, or "" if the path isn't for a single object.
+func changedType(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if plural, ok := dogTypes[segment]; ok {
+			return plural
+		}
+	}
+	return ""
+}
+
+func (c *listCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := c.next.RoundTrip(req)
+		if changed := changedType(req.URL.Path); changed != "" {
+			c.invalidate(changed)
+		}
+		return resp, err
+	}
+	list := listType(req.URL.Path)
+	if list == "" {
+		return c.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	c.mu.Lock()
+	cached, ok := c.responses[list][key]
+	generation := c.generations[list]
+	c.mu.Unlock()
+	if ok {
+		return cached.response(req), nil
+	}
+
+	// requests started before a change aren't shared with later ones
+	v, err, _ := c.group.Do(fmt.Sprintf("%d %s", generation, key), func() (any, error) {
+		resp, err := c.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		cached := cachedResponse{statusCode: resp.StatusCode, header: resp.Header.Clone(), body: body}
+		if resp.StatusCode == http.StatusOK {
+			c.mu.Lock()
+			if c.generations[list] == generation {
+				if c.responses[list] == nil {
+					c.responses[list] = map[string]cachedResponse{}
+				}
+				c.responses[list][key] = cached
+			}
+			c.mu.Unlock()
+		}
+		return cached, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(cachedResponse).response(req), nil
+}
+
+func (c *listCache) invalidate(list string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.responses, list)
+	c.generations[list]++
+}
+
+// response builds a new response for every caller, since each one reads and
+// closes its body.
+func (r cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.statusCode, http.StatusText(r.statusCode)),
+		StatusCode:    r.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
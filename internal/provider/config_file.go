@@ -0,0 +1,151 @@
+package dog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	api "github.com/relaypro-open/dog_api_golang/api"
+	"gopkg.in/yaml.v3"
+)
+
+// dogConfigFile is the shared config file of the provider and dog-import,
+// holding named connection profiles. dog-import/config_file.go has a copy of
+// this file that must be kept in sync with it.
+//
+//	profiles:
+//	  qa:
+//	    api_endpoint: https://qa-dog.example.com:8443/api/V2
+//	    api_token_command: vault kv get -field=token secret/dog/qa
+//	    ca_cert_file: ~/.config/dog/qa-ca.crt
+type dogConfigFile struct {
+	Profiles map[string]connectionProfile `yaml:"profiles"`
+}
+
+// connectionProfile holds the endpoint, token source and TLS settings of one
+// dog instance. The keys match the provider's attributes.
+type connectionProfile struct {
+	API_Endpoint              string `yaml:"api_endpoint"`
+	Api_Token                 string `yaml:"api_token"`
+	Api_Token_File            string `yaml:"api_token_file"`
+	Api_Token_Command         string `yaml:"api_token_command"`
+	Api_Token_Command_Timeout string `yaml:"api_token_command_timeout"`
+	CA_Cert_File              string `yaml:"ca_cert_file"`
+	Client_Cert_File          string `yaml:"client_cert_file"`
+	Client_Key_File           string `yaml:"client_key_file"`
+	Insecure_Skip_Verify      bool   `yaml:"insecure_skip_verify"`
+}
+
+// configFilePath returns DOG_CONFIG_FILE, or config.yaml in the dog directory
+// of XDG_CONFIG_HOME, which defaults to ~/.config.
+func configFilePath() (string, error) {
+	if path := os.Getenv("DOG_CONFIG_FILE"); path != "" {
+		return expandHome(path)
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dog", "config.yaml"), nil
+}
+
+// expandHome replaces a leading ~/ in path with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// loadConnectionProfile reads the named profile from the config file, with
+// the paths it holds expanded.
+func loadConnectionProfile(name string) (connectionProfile, error) {
+	var profile connectionProfile
+	path, err := configFilePath()
+	if err != nil {
+		return profile, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+	var config dogConfigFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return profile, fmt.Errorf("%s: %w", path, err)
+	}
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return profile, fmt.Errorf("%s has no profile %q", path, name)
+	}
+	for _, p := range []*string{&profile.Api_Token_File, &profile.CA_Cert_File, &profile.Client_Cert_File, &profile.Client_Key_File} {
+		if *p, err = expandHome(*p); err != nil {
+			return profile, err
+		}
+	}
+	return profile, nil
+}
+
+// applyTokenSource replaces the token source s, read from the environment,
+// with the profile's when it has one.
+func (profile connectionProfile) applyTokenSource(s *tokenSource) error {
+	if profile.Api_Token != "" || profile.Api_Token_File != "" || profile.Api_Token_Command != "" {
+		s.token = profile.Api_Token
+		s.file = profile.Api_Token_File
+		s.command = profile.Api_Token_Command
+	}
+	if profile.Api_Token_Command_Timeout != "" {
+		timeout, err := time.ParseDuration(profile.Api_Token_Command_Timeout)
+		if err != nil {
+			return fmt.Errorf("api_token_command_timeout must be a duration such as 30s, got: %q", profile.Api_Token_Command_Timeout)
+		}
+		s.timeout = timeout
+	}
+	return nil
+}
+
+// applyTLS configures the client's transport with the profile's TLS
+// settings. It must be called before the transport is wrapped.
+func (profile connectionProfile) applyTLS(c *api.Client) error {
+	if profile.CA_Cert_File == "" && profile.Client_Cert_File == "" && profile.Client_Key_File == "" && !profile.Insecure_Skip_Verify {
+		return nil
+	}
+	transport, err := c.Transport()
+	if err != nil {
+		return err
+	}
+	config := &tls.Config{InsecureSkipVerify: profile.Insecure_Skip_Verify}
+	if profile.CA_Cert_File != "" {
+		pem, err := os.ReadFile(profile.CA_Cert_File)
+		if err != nil {
+			return err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("%s holds no PEM certificates", profile.CA_Cert_File)
+		}
+	}
+	if profile.Client_Cert_File != "" || profile.Client_Key_File != "" {
+		cert, err := tls.LoadX509KeyPair(profile.Client_Cert_File, profile.Client_Key_File)
+		if err != nil {
+			return err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = config
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -115,29 +114,28 @@ func (d *groupDataSource) Configure(ctx context.Context, req datasource.Configur
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *dog.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.p.dog = client
+	d.p = *provider
 }
 
 func (d *groupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = d.p.logContext(ctx, "dog_group")
 	var state Group
 	var groupName string
 	var groupProfileId string
 
 	req.Config.GetAttribute(ctx, path.Root("name"), &groupName)
 	req.Config.GetAttribute(ctx, path.Root("profile_id"), &groupProfileId)
-	//tflog.Debug(ctx, fmt.Sprintf("ZZZgroupName: '%s'", groupName))
-	//tflog.Debug(ctx, fmt.Sprintf("ZZZgroupProfileId: '%s'", groupProfileId))
 
 	res, statusCode, err := d.p.dog.GetGroupsEncode(nil)
 	if (statusCode < 200 || statusCode > 299) && statusCode != 404 {
@@ -153,13 +151,11 @@ func (d *groupDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	var filteredGroupsName []api.Group
 	if groupName != "" {
 		filteredGroupsName = goterators.Filter(res, func(group api.Group) bool {
-			//tflog.Debug(ctx, fmt.Sprintf("ZZZgroup.Name: '%s', groupName: '%s'", group.Name,groupName))
 			return group.Name == groupName
 		})
 	} else {
 		filteredGroupsName = res
 	}
-	//tflog.Debug(ctx, spew.Sprint("ZZZfilteredGroupsName: %#v", filteredGroupsName))
 
 	var filteredGroupsProfileId []api.Group
 	if groupProfileId != "" {
@@ -169,11 +165,10 @@ func (d *groupDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	} else {
 		filteredGroupsProfileId = filteredGroupsName
 	}
-	//tflog.Debug(ctx, spew.Sprint("ZZZfilteredProfileId: %#v", filteredGroupsProfileId))
 
 	filteredGroups := filteredGroupsProfileId
 
-	tflog.Debug(ctx, spew.Sprint("ZZZfilteredGroups: %#v", filteredGroups))
+	tflog.Debug(ctx, "groups matching the dog_group filters", map[string]any{"groups": logValue(filteredGroups)})
 	if filteredGroups == nil {
 		resp.Diagnostics.AddError("Data Error", "dog_group data source returned no results.")
 	}
@@ -187,7 +182,6 @@ func (d *groupDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	group := filteredGroups[0]
 	// Set state
 	state = ApiToGroup(group)
-	//tflog.Debug(ctx, spew.Sprint("ZZZfilteredGroup: %#v", state))
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
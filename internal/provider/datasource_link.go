@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -151,21 +150,22 @@ func (d *linkDataSource) Configure(ctx context.Context, req datasource.Configure
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *dog.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.p.dog = client
+	d.p = *provider
 }
 
 func (d *linkDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = d.p.logContext(ctx, "dog_link")
 	var state Link
 	var linkName string
 
@@ -193,7 +193,7 @@ func (d *linkDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 
 	filteredLinks := filteredLinksName
 
-	tflog.Debug(ctx, spew.Sprint("ZZZfilteredLinks: %#v", filteredLinks))
+	tflog.Debug(ctx, "links matching the dog_link filters", map[string]any{"links": logValue(filteredLinks)})
 	if filteredLinks == nil {
 		resp.Diagnostics.AddError("Data Error", "dog_link data source returned no results.")
 	}
@@ -67,18 +67,18 @@ func (d *profileDataSource) Configure(ctx context.Context, req datasource.Config
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *dog.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.p.dog = client
+	d.p = *provider
 }
 
 type profileDataSourceData struct {
@@ -91,6 +91,7 @@ type profileDataSourceData struct {
 //}
 
 func (d *profileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = d.p.logContext(ctx, "dog_profile")
 	var state Profile
 	var profileName string
 
@@ -110,7 +111,6 @@ func (d *profileDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	var filteredProfilesName []api.Profile
 	if profileName != "" {
 		filteredProfilesName = goterators.Filter(res, func(profile api.Profile) bool {
-			//tflog.Debug(ctx, fmt.Sprintf("ZZZprofile.Name: '%s', profileName: '%s'", profile.Name,profileName))
 			return profile.Name == profileName
 		})
 	} else {
@@ -0,0 +1,207 @@
+package dog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type (
+	referencesDataSource struct {
+		p dogProvider
+	}
+
+	References struct {
+		Type     types.String        `tfsdk:"type"`
+		ID       types.String        `tfsdk:"id"`
+		Rulesets []RulesetReference  `tfsdk:"rulesets"`
+		Groups   []ReferencingObject `tfsdk:"groups"`
+		Hosts    []ReferencingObject `tfsdk:"hosts"`
+	}
+
+	RulesetReference struct {
+		ID        types.String `tfsdk:"id"`
+		Name      types.String `tfsdk:"name"`
+		Direction types.String `tfsdk:"direction"`
+		RuleIndex types.Int64  `tfsdk:"rule_index"`
+		Comment   types.String `tfsdk:"comment"`
+	}
+
+	ReferencingObject struct {
+		ID   types.String `tfsdk:"id"`
+		Name types.String `tfsdk:"name"`
+	}
+)
+
+var (
+	_ datasource.DataSource = (*referencesDataSource)(nil)
+)
+
+func NewReferencesDataSource() datasource.DataSource {
+	return &referencesDataSource{}
+}
+
+func (*referencesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_references"
+}
+
+func (*referencesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	object := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			MarkdownDescription: "Identifier",
+			Computed:            true,
+		},
+		"name": schema.StringAttribute{
+			MarkdownDescription: "Name",
+			Computed:            true,
+		},
+	}
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Ruleset rules, groups and hosts that refer to a zone, service, group or profile",
+
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Type of the referenced object: `zone`, `service`, `group` or `profile`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(referencedTypes...),
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the referenced object",
+				Required:            true,
+			},
+			"rulesets": schema.ListNestedAttribute{
+				MarkdownDescription: "Ruleset rules referring to a zone, service or group, one per rule, and rulesets " +
+					"referring to a profile",
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Ruleset identifier",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Ruleset name",
+							Computed:            true,
+						},
+						"direction": schema.StringAttribute{
+							MarkdownDescription: "`inbound` or `outbound`, null for a profile",
+							Computed:            true,
+						},
+						"rule_index": schema.Int64Attribute{
+							MarkdownDescription: "Index of the rule in the inbound or outbound list, from 0, null for a profile",
+							Computed:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Rule comment, null for a profile",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"groups": schema.ListNestedAttribute{
+				MarkdownDescription: "Groups using a profile",
+				Computed:            true,
+				NestedObject:        schema.NestedAttributeObject{Attributes: object},
+			},
+			"hosts": schema.ListNestedAttribute{
+				MarkdownDescription: "Hosts in a group",
+				Computed:            true,
+				NestedObject:        schema.NestedAttributeObject{Attributes: object},
+			},
+		},
+	}
+}
+
+func (d *referencesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*dogProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.p = *provider
+}
+
+func (d *referencesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state References
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	objectType := state.Type.ValueString()
+	id := state.ID.ValueString()
+	// hosts refer to their group by name
+	name := ""
+	if objectType == "group" {
+		group, statusCode, err := d.p.dog.GetGroupEncode(id, nil)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read group, got error: %s", err))
+		}
+		if statusCode != 200 {
+			resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		name = group.Name
+	}
+
+	references, err := d.p.findReferences(objectType, id, name)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to find references to %s %s: %s", objectType, id, err))
+		return
+	}
+
+	state.Rulesets = []RulesetReference{}
+	state.Groups = []ReferencingObject{}
+	state.Hosts = []ReferencingObject{}
+	for _, reference := range references {
+		object := ReferencingObject{
+			ID:   types.StringValue(reference.ID),
+			Name: types.StringValue(reference.Name),
+		}
+		switch reference.Type {
+		case "ruleset":
+			ruleset := RulesetReference{
+				ID:        object.ID,
+				Name:      object.Name,
+				Direction: types.StringNull(),
+				RuleIndex: types.Int64Null(),
+				Comment:   types.StringNull(),
+			}
+			if reference.Direction != "" {
+				ruleset.Direction = types.StringValue(reference.Direction)
+				ruleset.RuleIndex = types.Int64Value(int64(reference.RuleIndex))
+				ruleset.Comment = types.StringValue(reference.Comment)
+			}
+			state.Rulesets = append(state.Rulesets, ruleset)
+		case "group":
+			state.Groups = append(state.Groups, object)
+		case "host":
+			state.Hosts = append(state.Hosts, object)
+		}
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,399 @@
+package dog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type (
+	rulesetDocumentDataSource struct{}
+
+	RulesetDocument struct {
+		SourceDocuments   []string                   `tfsdk:"source_documents"`
+		OverrideDocuments []string                   `tfsdk:"override_documents"`
+		Rule              []RulesetDocumentRuleBlock `tfsdk:"rule"`
+		Inbound           []*rulesetResourceRule     `tfsdk:"inbound"`
+		Outbound          []*rulesetResourceRule     `tfsdk:"outbound"`
+		JSON              types.String               `tfsdk:"json"`
+	}
+
+	// RulesetDocumentRuleBlock is a rule block of a dog_ruleset_document,
+	// whose optional values are defaulted when the document is read.
+	RulesetDocumentRuleBlock struct {
+		Key          types.String `tfsdk:"key"`
+		Direction    types.String `tfsdk:"direction"`
+		Position     types.String `tfsdk:"position"`
+		Action       types.String `tfsdk:"action"`
+		Active       types.Bool   `tfsdk:"active"`
+		Comment      types.String `tfsdk:"comment"`
+		Environments []string     `tfsdk:"environments"`
+		Group        types.String `tfsdk:"group"`
+		GroupType    types.String `tfsdk:"group_type"`
+		Interface    types.String `tfsdk:"interface"`
+		Log          types.Bool   `tfsdk:"log"`
+		LogPrefix    types.String `tfsdk:"log_prefix"`
+		Service      types.String `tfsdk:"service"`
+		States       []string     `tfsdk:"states"`
+		Type         types.String `tfsdk:"type"`
+	}
+
+	// rulesetDocumentJSON is the json of a dog_ruleset_document, read back
+	// from source_documents and override_documents.
+	rulesetDocumentJSON struct {
+		Inbound  []documentRule `json:"inbound"`
+		Outbound []documentRule `json:"outbound"`
+	}
+
+	documentRule struct {
+		Key string `json:"key"`
+		Rule
+	}
+)
+
+var (
+	_ datasource.DataSource = (*rulesetDocumentDataSource)(nil)
+)
+
+func NewRulesetDocumentDataSource() datasource.DataSource {
+	return &rulesetDocumentDataSource{}
+}
+
+func (*rulesetDocumentDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_document"
+}
+
+func (*rulesetDocumentDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	rule := map[string]schema.Attribute{
+		"action":       schema.StringAttribute{Computed: true},
+		"active":       schema.BoolAttribute{Computed: true},
+		"comment":      schema.StringAttribute{Computed: true},
+		"environments": schema.ListAttribute{ElementType: types.StringType, Computed: true},
+		"group":        schema.StringAttribute{Computed: true},
+		"group_type":   schema.StringAttribute{Computed: true},
+		"interface":    schema.StringAttribute{Computed: true},
+		"log":          schema.BoolAttribute{Computed: true},
+		"log_prefix":   schema.StringAttribute{Computed: true},
+		"service":      schema.StringAttribute{Computed: true},
+		"states":       schema.ListAttribute{ElementType: types.StringType, Computed: true},
+		"type":         schema.StringAttribute{Computed: true},
+	}
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Ruleset rules composed from rule blocks and other documents, for the rules of a dog_ruleset",
+
+		Attributes: map[string]schema.Attribute{
+			"source_documents": schema.ListAttribute{
+				MarkdownDescription: "`json` of other dog_ruleset_document data sources, whose rules come first, in order. " +
+					"A key may only be used once per direction across them.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"override_documents": schema.ListAttribute{
+				MarkdownDescription: "`json` of other dog_ruleset_document data sources, applied in order after the rule blocks. " +
+					"Their rules replace the rules with the same key in place, other rules are added at the tail.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"inbound": schema.ListNestedAttribute{
+				MarkdownDescription: "Inbound rules, for `rules.inbound` of a dog_ruleset",
+				Computed:            true,
+				NestedObject:        schema.NestedAttributeObject{Attributes: rule},
+			},
+			"outbound": schema.ListNestedAttribute{
+				MarkdownDescription: "Outbound rules, for `rules.outbound` of a dog_ruleset",
+				Computed:            true,
+				NestedObject:        schema.NestedAttributeObject{Attributes: rule},
+			},
+			"json": schema.StringAttribute{
+				MarkdownDescription: "The rules with their keys, for the `source_documents` or `override_documents` of another dog_ruleset_document",
+				Computed:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rule": schema.ListNestedBlock{
+				MarkdownDescription: "Rule of the document. A rule with the key of a rule from `source_documents` replaces it in place.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Name of the rule, unique per direction, that other documents override it by",
+							Required:            true,
+							Validators:          []validator.String{stringvalidator.LengthAtLeast(1)},
+						},
+						"direction": schema.StringAttribute{
+							MarkdownDescription: "`inbound` or `outbound`",
+							Required:            true,
+							Validators:          []validator.String{stringvalidator.OneOf("inbound", "outbound")},
+						},
+						"position": schema.StringAttribute{
+							MarkdownDescription: "`head` to insert the rule before the rules of `source_documents`, or `tail` after them. " +
+								"Defaults to `tail`.",
+							Optional:   true,
+							Validators: []validator.String{stringvalidator.OneOf("head", "tail")},
+						},
+						"action": schema.StringAttribute{
+							Required:   true,
+							Validators: []validator.String{stringvalidator.OneOf("ACCEPT", "DROP", "REJECT")},
+						},
+						"active": schema.BoolAttribute{
+							MarkdownDescription: "Defaults to true",
+							Optional:            true,
+						},
+						"comment": schema.StringAttribute{
+							Optional: true,
+						},
+						"environments": schema.ListAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"group": schema.StringAttribute{
+							MarkdownDescription: "Defaults to `any`",
+							Optional:            true,
+						},
+						"group_type": schema.StringAttribute{
+							MarkdownDescription: "Defaults to `ANY`",
+							Optional:            true,
+							Validators:          []validator.String{stringvalidator.OneOf("ANY", "GROUP", "ROLE", "ZONE")},
+						},
+						"interface": schema.StringAttribute{
+							Optional: true,
+						},
+						"log": schema.BoolAttribute{
+							MarkdownDescription: "Defaults to false",
+							Optional:            true,
+						},
+						"log_prefix": schema.StringAttribute{
+							Optional: true,
+						},
+						"service": schema.StringAttribute{
+							MarkdownDescription: "Defaults to `any`",
+							Optional:            true,
+						},
+						"states": schema.ListAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Validators: []validator.List{
+								listvalidator.ValueStringsAre(stringvalidator.OneOf("NEW", "ESTABLISHED", "RELATED", "INVALID")),
+							},
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Defaults to `BASIC`",
+							Optional:            true,
+							Validators:          []validator.String{stringvalidator.OneOf("BASIC")},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *rulesetDocumentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state RulesetDocument
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	document := rulesetDocumentJSON{Inbound: []documentRule{}, Outbound: []documentRule{}}
+	for i, source := range state.SourceDocuments {
+		at := path.Root("source_documents").AtListIndex(i)
+		sourceDocument, ok := readRulesetDocument(source, at, &resp.Diagnostics)
+		if !ok {
+			continue
+		}
+		for _, direction := range []string{"inbound", "outbound"} {
+			rules := document.rules(direction)
+			for _, rule := range *sourceDocument.rules(direction) {
+				if indexOfKey(*rules, rule.Key) >= 0 {
+					resp.Diagnostics.AddAttributeError(at, "Duplicate Rule Key",
+						fmt.Sprintf("The %s rule key %q is used by more than one source document. Use override_documents to replace a rule.", direction, rule.Key))
+					continue
+				}
+				*rules = append(*rules, rule)
+			}
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	head := rulesetDocumentJSON{Inbound: []documentRule{}, Outbound: []documentRule{}}
+	tail := rulesetDocumentJSON{Inbound: []documentRule{}, Outbound: []documentRule{}}
+	blockKeys := map[string]bool{}
+	for i, block := range state.Rule {
+		direction := block.Direction.ValueString()
+		rule := block.documentRule()
+		if blockKeys[direction+" "+rule.Key] {
+			resp.Diagnostics.AddAttributeError(path.Root("rule").AtListIndex(i).AtName("key"), "Duplicate Rule Key",
+				fmt.Sprintf("The %s rule key %q is used by more than one rule block.", direction, rule.Key))
+			continue
+		}
+		blockKeys[direction+" "+rule.Key] = true
+		rules := document.rules(direction)
+		if j := indexOfKey(*rules, rule.Key); j >= 0 {
+			(*rules)[j] = rule
+			continue
+		}
+		if block.Position.ValueString() == "head" {
+			*head.rules(direction) = append(*head.rules(direction), rule)
+		} else {
+			*tail.rules(direction) = append(*tail.rules(direction), rule)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, direction := range []string{"inbound", "outbound"} {
+		rules := document.rules(direction)
+		*rules = append(append(*head.rules(direction), *rules...), *tail.rules(direction)...)
+	}
+
+	for i, override := range state.OverrideDocuments {
+		overrideDocument, ok := readRulesetDocument(override, path.Root("override_documents").AtListIndex(i), &resp.Diagnostics)
+		if !ok {
+			continue
+		}
+		for _, direction := range []string{"inbound", "outbound"} {
+			rules := document.rules(direction)
+			for _, rule := range *overrideDocument.rules(direction) {
+				if j := indexOfKey(*rules, rule.Key); j >= 0 {
+					(*rules)[j] = rule
+				} else {
+					*rules = append(*rules, rule)
+				}
+			}
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		resp.Diagnostics.AddError("Document Error", fmt.Sprintf("Unable to encode the ruleset document: %s", err))
+		return
+	}
+	state.JSON = types.StringValue(string(documentJSON))
+	state.Inbound = documentRulesToRuleset(document.Inbound)
+	state.Outbound = documentRulesToRuleset(document.Outbound)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// readRulesetDocument decodes the json of a dog_ruleset_document given in the
+// attribute at.
+func readRulesetDocument(documentJSON string, at path.Path, diags *diag.Diagnostics) (rulesetDocumentJSON, bool) {
+	var document rulesetDocumentJSON
+	if err := json.Unmarshal([]byte(documentJSON), &document); err != nil {
+		diags.AddAttributeError(at, "Invalid Ruleset Document",
+			fmt.Sprintf("The value must be the json of a dog_ruleset_document: %s", err))
+		return document, false
+	}
+	for _, direction := range []string{"inbound", "outbound"} {
+		for _, rule := range *document.rules(direction) {
+			if rule.Key == "" {
+				diags.AddAttributeError(at, "Invalid Ruleset Document",
+					fmt.Sprintf("Every %s rule of the document must have a key.", direction))
+				return document, false
+			}
+		}
+	}
+	return document, true
+}
+
+func (d *rulesetDocumentJSON) rules(direction string) *[]documentRule {
+	if direction == "inbound" {
+		return &d.Inbound
+	}
+	return &d.Outbound
+}
+
+func indexOfKey(rules []documentRule, key string) int {
+	for i, rule := range rules {
+		if rule.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// documentRule returns the rule of a rule block, with the defaults of its
+// unset values.
+func (b RulesetDocumentRuleBlock) documentRule() documentRule {
+	stringOr := func(v types.String, defaultValue string) string {
+		if v.IsNull() {
+			return defaultValue
+		}
+		return v.ValueString()
+	}
+	boolOr := func(v types.Bool, defaultValue bool) bool {
+		if v.IsNull() {
+			return defaultValue
+		}
+		return v.ValueBool()
+	}
+	rule := documentRule{
+		Key: b.Key.ValueString(),
+		Rule: Rule{
+			Action:       b.Action.ValueString(),
+			Active:       boolOr(b.Active, true),
+			Comment:      b.Comment.ValueString(),
+			Environments: b.Environments,
+			Group:        stringOr(b.Group, "any"),
+			GroupType:    stringOr(b.GroupType, "ANY"),
+			Interface:    b.Interface.ValueString(),
+			Log:          boolOr(b.Log, false),
+			LogPrefix:    b.LogPrefix.ValueString(),
+			Service:      stringOr(b.Service, "any"),
+			States:       b.States,
+			Type:         stringOr(b.Type, "BASIC"),
+		},
+	}
+	if rule.Environments == nil {
+		rule.Environments = []string{}
+	}
+	if rule.States == nil {
+		rule.States = []string{}
+	}
+	return rule
+}
+
+func documentRulesToRuleset(rules []documentRule) []*rulesetResourceRule {
+	ruleset := []*rulesetResourceRule{}
+	for _, rule := range rules {
+		environments, states := rule.Environments, rule.States
+		if environments == nil {
+			environments = []string{}
+		}
+		if states == nil {
+			states = []string{}
+		}
+		ruleset = append(ruleset, &rulesetResourceRule{
+			Action:       types.StringValue(rule.Action),
+			Active:       types.BoolValue(rule.Active),
+			Comment:      types.StringValue(rule.Comment),
+			Environments: environments,
+			Group:        types.StringValue(rule.Group),
+			GroupType:    types.StringValue(rule.GroupType),
+			Interface:    types.StringValue(rule.Interface),
+			Log:          types.BoolValue(rule.Log),
+			LogPrefix:    types.StringValue(rule.LogPrefix),
+			Service:      types.StringValue(rule.Service),
+			States:       states,
+			Type:         types.StringValue(rule.Type),
+		})
+	}
+	return ruleset
+}
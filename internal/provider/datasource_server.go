@@ -0,0 +1,90 @@
+package dog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type (
+	serverDataSource struct {
+		p dogProvider
+	}
+
+	Server struct {
+		ID       types.String    `tfsdk:"id"`
+		Version  types.String    `tfsdk:"version"`
+		Features map[string]bool `tfsdk:"features"`
+	}
+)
+
+var (
+	_ datasource.DataSource = (*serverDataSource)(nil)
+)
+
+func NewServerDataSource() datasource.DataSource {
+	return &serverDataSource{}
+}
+
+func (*serverDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_server"
+}
+
+func (*serverDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Version and features of the dog trainer, detected when the provider is configured",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "API endpoint URL",
+				Computed:            true,
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: "dog version, null when dog doesn't report it or skip_version_check is set",
+				Computed:            true,
+			},
+			"features": schema.MapAttribute{
+				MarkdownDescription: "Whether the dog trainer has each feature the provider knows of. All are true when dog doesn't report its version or skip_version_check is set",
+				ElementType:         types.BoolType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *serverDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*dogProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.p = *provider
+}
+
+func (d *serverDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	state := Server{
+		ID:       types.StringValue(d.p.dog.BaseURL),
+		Version:  types.StringNull(),
+		Features: d.p.server.features,
+	}
+	if v := d.p.server.versionString(); v != "" {
+		state.Version = types.StringValue(v)
+	}
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"reflect"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -96,18 +95,18 @@ func (d *serviceDataSource) Configure(ctx context.Context, req datasource.Config
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *dog.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.p.dog = client
+	d.p = *provider
 }
 
 type serviceDataSourceData struct {
@@ -116,13 +115,18 @@ type serviceDataSourceData struct {
 }
 
 func (d *serviceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = d.p.logContext(ctx, "dog_service")
 	var state Service
 	var serviceName string
 	var serviceServices []*PortProtocol
 
 	req.Config.GetAttribute(ctx, path.Root("name"), &serviceName)
 	req.Config.GetAttribute(ctx, path.Root("services"), &serviceServices)
-	tflog.Debug(ctx, spew.Sprint("ZZZ serviceServices: %#v", serviceServices))
+	filter := []*api.PortProtocol{}
+	for _, portProtocol := range serviceServices {
+		filter = append(filter, &api.PortProtocol{Ports: portProtocol.Ports, Protocol: portProtocol.Protocol.ValueString()})
+	}
+	tflog.Debug(ctx, "dog_service services filter", map[string]any{"services": logValue(filter)})
 
 	res, statusCode, err := d.p.dog.GetServices(nil)
 	if (statusCode < 200 || statusCode > 299) && statusCode != 404 {
@@ -142,24 +146,23 @@ func (d *serviceDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	} else {
 		filteredServicesName = res
 	}
-	tflog.Debug(ctx, spew.Sprint("ZZZfilteredServicesName: %#v", filteredServicesName))
+	tflog.Debug(ctx, "services matching the dog_service name", map[string]any{"services": logValue(filteredServicesName)})
 
 	var filteredServicesProtocol []api.Service
 	if serviceServices != nil {
 		filteredServicesProtocol = goterators.Filter(filteredServicesName, func(service api.Service) bool {
 			convertedServices := ApiToService(service)
-			tflog.Debug(ctx, spew.Sprint("ZZZ serviceServices: %#v, convertedServices: %#v", serviceServices, convertedServices.Services))
-			tflog.Debug(ctx, spew.Sprint("ZZZ service DeepEqual: %#v", reflect.DeepEqual(serviceServices, convertedServices.Services)))
-			return reflect.DeepEqual(serviceServices, convertedServices.Services)
+			match := reflect.DeepEqual(serviceServices, convertedServices.Services)
+			tflog.Debug(ctx, "comparing service with the dog_service services filter", map[string]any{"service": logValue(service), "match": match})
+			return match
 		})
 	} else {
 		filteredServicesProtocol = filteredServicesName
 	}
-	tflog.Debug(ctx, spew.Sprint("ZZZfilteredServicesProtocol: %#v", filteredServicesProtocol))
 
 	filteredServices := filteredServicesProtocol
 
-	tflog.Debug(ctx, spew.Sprint("ZZZfilteredServices: %#v", filteredServices))
+	tflog.Debug(ctx, "services matching the dog_service filters", map[string]any{"services": logValue(filteredServices)})
 	if filteredServices == nil {
 		resp.Diagnostics.AddError("Data Error", "dog_service data source returned no results.")
 	}
@@ -0,0 +1,36 @@
+package dog
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// deletionProtectionAttribute is the deletion_protection attribute of the
+// resources whose Delete checks it. It is only kept in the Terraform state,
+// dog knows nothing about it.
+func deletionProtectionAttribute(resourceType string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		MarkdownDescription: fmt.Sprintf("Refuse to delete this %s, including when it is replaced, while true. "+
+			"Set it to false and apply before removing the resource. Defaults to false.", resourceType),
+		Optional: true,
+	}
+}
+
+// protectedFromDeletion adds an error and returns true when the state of a
+// resource has deletion_protection set. The state holds the value of the last
+// apply, so protection is only lifted by an apply that leaves the resource in
+// place.
+func protectedFromDeletion(diags *diag.Diagnostics, resourceType string, name string, deletionProtection types.Bool) bool {
+	if !deletionProtection.ValueBool() {
+		return false
+	}
+	diags.AddError(
+		"Deletion Protection Enabled",
+		fmt.Sprintf("Refusing to delete %s %q, it has deletion_protection set. "+
+			"To delete it, set deletion_protection = false and apply, then remove it in a separate apply.", resourceType, name),
+	)
+	return true
+}
@@ -0,0 +1,142 @@
+package dog
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = (*normalizeCidrsFunction)(nil)
+)
+
+type normalizeCidrsFunction struct{}
+
+func NewNormalizeCidrsFunction() function.Function {
+	return &normalizeCidrsFunction{}
+}
+
+func (*normalizeCidrsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "normalize_cidrs"
+}
+
+func (*normalizeCidrsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Canonicalize and aggregate zone addresses",
+		MarkdownDescription: "Turns a list of IPv4 and IPv6 addresses and CIDRs into the shortest equivalent list for a dog_zone: " +
+			"host bits are cleared, duplicates and CIDRs inside other CIDRs are dropped, adjacent CIDRs are merged, " +
+			"single addresses are written without a prefix length, and the result is sorted with IPv4 first.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "addresses",
+				MarkdownDescription: "Addresses and CIDRs, such as `10.0.0.1` or `10.0.0.0/24`",
+				ElementType:         types.StringType,
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (*normalizeCidrsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var addresses []string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &addresses))
+	if resp.Error != nil {
+		return
+	}
+	normalized, err := normalizeCidrs(addresses)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, normalized))
+}
+
+// parseAddress parses an address or CIDR, an address being a CIDR of one
+// host.
+func parseAddress(address string) (netip.Prefix, error) {
+	address = strings.TrimSpace(address)
+	if strings.Contains(address, "/") {
+		prefix, err := netip.ParsePrefix(address)
+		if err != nil {
+			return prefix, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// formatAddress writes a CIDR of one host as its address, like dog shows
+// zone addresses.
+func formatAddress(prefix netip.Prefix) string {
+	if prefix.IsSingleIP() {
+		return prefix.Addr().String()
+	}
+	return prefix.String()
+}
+
+// normalizeCidrs returns the shortest sorted list of CIDRs covering the same
+// addresses as the given list.
+func normalizeCidrs(addresses []string) ([]string, error) {
+	prefixes := []netip.Prefix{}
+	for _, address := range addresses {
+		prefix, err := parseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %s", address, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	// sorted by family and address, with shorter prefixes first, a CIDR is
+	// followed by the CIDRs inside it
+	sortPrefixes(prefixes)
+	aggregated := []netip.Prefix{}
+	for _, prefix := range prefixes {
+		if n := len(aggregated); n > 0 && aggregated[n-1].Overlaps(prefix) {
+			continue
+		}
+		aggregated = append(aggregated, prefix)
+		// merge the new CIDR with its sibling for as long as possible
+		for n := len(aggregated); n > 1; n = len(aggregated) {
+			last, previous := aggregated[n-1], aggregated[n-2]
+			if last.Bits() != previous.Bits() || last.Bits() == 0 {
+				break
+			}
+			parent, _ := last.Addr().Prefix(last.Bits() - 1)
+			if parent.Addr() != previous.Addr() {
+				break
+			}
+			aggregated = append(aggregated[:n-2], parent)
+		}
+	}
+
+	normalized := []string{}
+	for _, prefix := range aggregated {
+		normalized = append(normalized, formatAddress(prefix))
+	}
+	return normalized, nil
+}
+
+func sortPrefixes(prefixes []netip.Prefix) {
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i], prefixes[j]
+		if a.Addr().Is4() != b.Addr().Is4() {
+			return a.Addr().Is4()
+		}
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+}
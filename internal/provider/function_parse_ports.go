@@ -0,0 +1,109 @@
+package dog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = (*parsePortsFunction)(nil)
+)
+
+type parsePortsFunction struct{}
+
+func NewParsePortsFunction() function.Function {
+	return &parsePortsFunction{}
+}
+
+func (*parsePortsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_ports"
+}
+
+func (*parsePortsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Parse a list of ports into dog's ports format",
+		MarkdownDescription: "Turns a comma separated list of ports and ranges, such as `80,443,8000-8100`, into the `ports` " +
+			"list of a dog_service, with ranges written as `8000:8100`. Ranges may also be given as `8000:8100`. " +
+			"Duplicates are dropped and the order is kept.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "ports",
+				MarkdownDescription: "Comma separated ports and ranges",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (*parsePortsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ports string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &ports))
+	if resp.Error != nil {
+		return
+	}
+	parsed, err := parsePorts(ports)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, parsed))
+}
+
+func parsePort(port string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(port))
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("invalid port %q, must be between 1 and 65535", strings.TrimSpace(port))
+	}
+	return n, nil
+}
+
+// parsePorts parses "80,443,8000-8100" into ["80", "443", "8000:8100"].
+func parsePorts(ports string) ([]string, error) {
+	parsed := []string{}
+	if strings.TrimSpace(ports) == "" {
+		return parsed, nil
+	}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(ports, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, fmt.Errorf("empty port in %q", ports)
+		}
+		var port string
+		if from, to, ok := strings.Cut(strings.Replace(item, "-", ":", 1), ":"); ok {
+			first, err := parsePort(from)
+			if err != nil {
+				return nil, err
+			}
+			last, err := parsePort(to)
+			if err != nil {
+				return nil, err
+			}
+			if first > last {
+				return nil, fmt.Errorf("invalid range %q, %d is after %d", item, first, last)
+			}
+			port = fmt.Sprintf("%d:%d", first, last)
+			if first == last {
+				port = strconv.Itoa(first)
+			}
+		} else {
+			n, err := parsePort(item)
+			if err != nil {
+				return nil, err
+			}
+			port = strconv.Itoa(n)
+		}
+		if !seen[port] {
+			seen[port] = true
+			parsed = append(parsed, port)
+		}
+	}
+	return parsed, nil
+}
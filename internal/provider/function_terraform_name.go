@@ -0,0 +1,67 @@
+package dog
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var (
+	_ function.Function = (*terraformNameFunction)(nil)
+)
+
+type terraformNameFunction struct{}
+
+func NewTerraformNameFunction() function.Function {
+	return &terraformNameFunction{}
+}
+
+func (*terraformNameFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "terraform_name"
+}
+
+func (*terraformNameFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Turn a dog object name into a Terraform identifier",
+		MarkdownDescription: "Returns the Terraform identifier dog-import uses for a dog object name: anything other than " +
+			"letters, digits, `_` and `-` becomes `_`, and a name that doesn't start with a letter or `_` is prefixed " +
+			"with `_`. dog-import adds `_2`, `_3`, ... suffixes when several names of a table give the same identifier, " +
+			"which a single name can't show.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "name",
+				MarkdownDescription: "dog object name",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (*terraformNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &name))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, toTerraformName(name)))
+}
+
+// invalidNameCharsRe and toTerraformName follow dog-import's naming.go, so
+// that configurations can refer to the names it writes.
+var invalidNameCharsRe = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// toTerraformName turns a dog object name into a valid Terraform identifier:
+// anything other than letters, digits, '_' and '-' becomes '_', and names
+// that don't start with a letter or '_' are prefixed with '_'.
+func toTerraformName(name string) string {
+	tfName := invalidNameCharsRe.ReplaceAllString(name, "_")
+	if tfName == "" {
+		return "_"
+	}
+	first := tfName[0]
+	if !(first == '_' || (first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z')) {
+		tfName = "_" + tfName
+	}
+	return tfName
+}
@@ -0,0 +1,317 @@
+package dog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// policyModel is a policy block of the provider configuration.
+type policyModel struct {
+	Name           types.String `tfsdk:"name"`
+	Severity       types.String `tfsdk:"severity"`
+	Message        types.String `tfsdk:"message"`
+	Direction      types.String `tfsdk:"direction"`
+	Action         types.String `tfsdk:"action"`
+	Group          types.String `tfsdk:"group"`
+	GroupType      types.String `tfsdk:"group_type"`
+	Service        types.String `tfsdk:"service"`
+	Environments   types.List   `tfsdk:"environments"`
+	Deny           types.Bool   `tfsdk:"deny"`
+	RequireComment types.Bool   `tfsdk:"require_comment"`
+	RequireLog     types.Bool   `tfsdk:"require_log"`
+}
+
+// rulesetPolicy is a policy that the rules of every dog_ruleset are checked
+// against when planning. A rule matches when it has every one of the set
+// match values, and a matching rule violates the policy when the policy
+// denies it or when it lacks a required comment or log.
+type rulesetPolicy struct {
+	name     string
+	warning  bool
+	message  string
+	requires []string
+
+	direction    string
+	action       string
+	group        string
+	groupType    string
+	service      string
+	environments []string
+}
+
+func policyBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "Policy that the rules of every dog_ruleset must follow, checked when planning. " +
+			"A rule matches a policy when it has all of the policy's direction, action, group, group_type, " +
+			"service and environments that are set; a policy without any of them matches every rule.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					MarkdownDescription: "Policy name, shown with each violation",
+					Required:            true,
+				},
+				"severity": schema.StringAttribute{
+					MarkdownDescription: "`error` to fail the plan on a violation, or `warning`. Defaults to `error`.",
+					Optional:            true,
+					Validators:          []validator.String{stringvalidator.OneOf("error", "warning")},
+				},
+				"message": schema.StringAttribute{
+					MarkdownDescription: "Explanation shown with each violation, instead of the default one",
+					Optional:            true,
+				},
+				"direction": schema.StringAttribute{
+					MarkdownDescription: "Only match `inbound` or `outbound` rules",
+					Optional:            true,
+					Validators:          []validator.String{stringvalidator.OneOf("inbound", "outbound")},
+				},
+				"action": schema.StringAttribute{
+					MarkdownDescription: "Only match rules with this action, such as `ACCEPT`",
+					Optional:            true,
+				},
+				"group": schema.StringAttribute{
+					MarkdownDescription: "Only match rules with this group, such as `any` or a zone or group ID",
+					Optional:            true,
+				},
+				"group_type": schema.StringAttribute{
+					MarkdownDescription: "Only match rules with this group type, such as `ZONE`",
+					Optional:            true,
+				},
+				"service": schema.StringAttribute{
+					MarkdownDescription: "Only match rules with this service, such as `any` or a service ID",
+					Optional:            true,
+				},
+				"environments": schema.ListAttribute{
+					MarkdownDescription: "Only match rules applying to one of these environments. A rule without environments applies to all of them.",
+					Optional:            true,
+					ElementType:         types.StringType,
+				},
+				"deny": schema.BoolAttribute{
+					MarkdownDescription: "Matching rules are not allowed",
+					Optional:            true,
+				},
+				"require_comment": schema.BoolAttribute{
+					MarkdownDescription: "Matching rules must have a non-empty comment",
+					Optional:            true,
+				},
+				"require_log": schema.BoolAttribute{
+					MarkdownDescription: "Matching rules must have log set to true",
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// newRulesetPolicies checks the policy blocks of the provider configuration,
+// whose values must all be known.
+func newRulesetPolicies(ctx context.Context, models []policyModel, diags *diag.Diagnostics) []rulesetPolicy {
+	policies := []rulesetPolicy{}
+	for i, m := range models {
+		at := path.Root("policy").AtListIndex(i)
+		for _, v := range []attr.Value{m.Name, m.Severity, m.Message, m.Direction, m.Action, m.Group, m.GroupType, m.Service, m.Environments, m.Deny, m.RequireComment, m.RequireLog} {
+			if v.IsUnknown() {
+				diags.AddAttributeError(at, "Unknown Dog Policy",
+					"The provider cannot check rulesets against a policy with unknown configuration values. Set the policy's values statically in the configuration.")
+				return nil
+			}
+		}
+		policy := rulesetPolicy{
+			name:      m.Name.ValueString(),
+			warning:   m.Severity.ValueString() == "warning",
+			message:   m.Message.ValueString(),
+			direction: m.Direction.ValueString(),
+			action:    m.Action.ValueString(),
+			group:     m.Group.ValueString(),
+			groupType: m.GroupType.ValueString(),
+			service:   m.Service.ValueString(),
+		}
+		diags.Append(m.Environments.ElementsAs(ctx, &policy.environments, false)...)
+		if m.Deny.ValueBool() {
+			policy.requires = append(policy.requires, "deny")
+		}
+		if m.RequireComment.ValueBool() {
+			policy.requires = append(policy.requires, "comment")
+		}
+		if m.RequireLog.ValueBool() {
+			policy.requires = append(policy.requires, "log")
+		}
+		if len(policy.requires) == 0 {
+			diags.AddAttributeError(at, "Invalid Dog Policy",
+				fmt.Sprintf("Policy %q doesn't check anything: set deny, require_comment or require_log.", policy.name))
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// policyRule reads the attributes of a rule in a plan. Rules are read as
+// objects rather than rulesetResourceRule, since any of their values may be
+// unknown while planning.
+type policyRule map[string]attr.Value
+
+// stringValue returns a string attribute of the rule, and whether it is known.
+func (r policyRule) stringValue(name string) (string, bool) {
+	v, ok := r[name].(types.String)
+	if !ok || v.IsUnknown() {
+		return "", false
+	}
+	return v.ValueString(), true
+}
+
+func (r policyRule) boolValue(name string) (bool, bool) {
+	v, ok := r[name].(types.Bool)
+	if !ok || v.IsUnknown() {
+		return false, false
+	}
+	return v.ValueBool(), true
+}
+
+// environments returns the environments of the rule, and whether they are
+// known.
+func (r policyRule) environments() ([]string, bool) {
+	v, ok := r["environments"].(types.List)
+	if !ok || v.IsUnknown() {
+		return nil, false
+	}
+	environments := []string{}
+	for _, e := range v.Elements() {
+		s, ok := e.(types.String)
+		if !ok || s.IsUnknown() {
+			return nil, false
+		}
+		environments = append(environments, s.ValueString())
+	}
+	return environments, true
+}
+
+// matches reports whether the rule matches the policy. A rule with an unknown
+// value that the policy matches on doesn't match yet; it is checked again
+// when the apply is planned with the final values.
+func (p rulesetPolicy) matches(direction string, rule policyRule) bool {
+	if p.direction != "" && p.direction != direction {
+		return false
+	}
+	for name, want := range map[string]string{"action": p.action, "group": p.group, "group_type": p.groupType, "service": p.service} {
+		if want == "" {
+			continue
+		}
+		if v, known := rule.stringValue(name); !known || !strings.EqualFold(v, want) {
+			return false
+		}
+	}
+	if len(p.environments) > 0 {
+		environments, known := rule.environments()
+		if !known {
+			return false
+		}
+		if len(environments) > 0 && !containsAny(environments, p.environments) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check adds a diagnostic for each requirement of the policy that a matching
+// rule, at the given path, doesn't meet.
+func (p rulesetPolicy) check(at path.Path, rule policyRule, diags *diag.Diagnostics) {
+	for _, requirement := range p.requires {
+		var violation string
+		violationPath := at
+		switch requirement {
+		case "deny":
+			violation = "rules matching it are not allowed"
+		case "comment":
+			if comment, known := rule.stringValue("comment"); known && strings.TrimSpace(comment) == "" {
+				violation = "the rule must have a comment"
+				violationPath = at.AtName("comment")
+			}
+		case "log":
+			if log, known := rule.boolValue("log"); known && !log {
+				violation = "the rule must have log = true"
+				violationPath = at.AtName("log")
+			}
+		}
+		if violation == "" {
+			continue
+		}
+		if p.message != "" {
+			violation = p.message
+		}
+		detail := fmt.Sprintf("The rule breaks the dog provider policy %q: %s.", p.name, violation)
+		if p.warning {
+			diags.AddAttributeWarning(violationPath, "Ruleset Policy Violation", detail)
+		} else {
+			diags.AddAttributeError(violationPath, "Ruleset Policy Violation", detail)
+		}
+	}
+}
+
+// checkRulesetPolicies checks the inbound and outbound rules of a planned
+// dog_ruleset, in rules or keyed_rules, against the provider's policies.
+func checkRulesetPolicies(ctx context.Context, policies []rulesetPolicy, plan tfsdk.Plan, diags *diag.Diagnostics) {
+	if len(policies) == 0 {
+		return
+	}
+	for _, direction := range []string{"inbound", "outbound"} {
+		at := path.Root("rules").AtName(direction)
+		var rules types.List
+		getDiags := plan.GetAttribute(ctx, at, &rules)
+		diags.Append(getDiags...)
+		if !getDiags.HasError() && !rules.IsNull() && !rules.IsUnknown() {
+			for i, element := range rules.Elements() {
+				checkRulePolicies(policies, direction, at.AtListIndex(i), element, diags)
+			}
+		}
+
+		at = path.Root("keyed_rules").AtName(direction)
+		var keyedRules types.Map
+		getDiags = plan.GetAttribute(ctx, at, &keyedRules)
+		diags.Append(getDiags...)
+		if !getDiags.HasError() && !keyedRules.IsNull() && !keyedRules.IsUnknown() {
+			elements := keyedRules.Elements()
+			keys := make([]string, 0, len(elements))
+			for key := range elements {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				checkRulePolicies(policies, direction, at.AtMapKey(key), elements[key], diags)
+			}
+		}
+	}
+}
+
+func checkRulePolicies(policies []rulesetPolicy, direction string, at path.Path, element attr.Value, diags *diag.Diagnostics) {
+	object, ok := element.(types.Object)
+	if !ok || object.IsNull() || object.IsUnknown() {
+		return
+	}
+	rule := policyRule(object.Attributes())
+	for _, policy := range policies {
+		if policy.matches(direction, rule) {
+			policy.check(at, rule, diags)
+		}
+	}
+}
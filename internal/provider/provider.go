@@ -3,14 +3,17 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	api "github.com/relaypro-open/dog_api_golang/api"
 )
 
@@ -18,18 +21,34 @@ type (
 	dogProvider struct {
 		dog        *api.Client
 		configured bool
+		readOnly   bool
+		// apiToken is only kept to mask it in logs
+		apiToken string
+		auditLog *auditLog
+		server   dogServer
+		policies []rulesetPolicy
 
 		version string
 	}
 
 	dogProviderModel struct {
-		Api_Token    types.String `tfsdk:"api_token"`
-		API_Endpoint types.String `tfsdk:"api_endpoint"`
+		Profile                   types.String  `tfsdk:"profile"`
+		Api_Token                 types.String  `tfsdk:"api_token"`
+		Api_Token_File            types.String  `tfsdk:"api_token_file"`
+		Api_Token_Command         types.String  `tfsdk:"api_token_command"`
+		Api_Token_Command_Timeout types.String  `tfsdk:"api_token_command_timeout"`
+		API_Endpoint              types.String  `tfsdk:"api_endpoint"`
+		Cache_List_Responses      types.Bool    `tfsdk:"cache_list_responses"`
+		Read_Only                 types.Bool    `tfsdk:"read_only"`
+		Audit_Log_Path            types.String  `tfsdk:"audit_log_path"`
+		Skip_Version_Check        types.Bool    `tfsdk:"skip_version_check"`
+		Policy                    []policyModel `tfsdk:"policy"`
 	}
 )
 
 var (
-	_ provider.Provider = (*dogProvider)(nil)
+	_ provider.Provider              = (*dogProvider)(nil)
+	_ provider.ProviderWithFunctions = (*dogProvider)(nil)
 	//_ provider.ProviderWithMetadata = (*dogProvider)(nil)
 )
 
@@ -48,6 +67,10 @@ func (p *dogProvider) Metadata(_ context.Context, req provider.MetadataRequest,
 func (*dogProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of a connection profile in ~/.config/dog/config.yaml, or the file named by DOG_CONFIG_FILE, holding the API endpoint, API key source and TLS settings. Attributes set in the configuration take precedence over the profile, and the profile over the environment variables. Can also be set with the DOG_PROFILE environment variable.",
+				Optional:            true,
+			},
 			"api_endpoint": schema.StringAttribute{
 				MarkdownDescription: "API endpoint URL",
 				Optional:            true,
@@ -55,7 +78,39 @@ func (*dogProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp
 			"api_token": schema.StringAttribute{
 				MarkdownDescription: "API Key",
 				Optional:            true,
+				Sensitive:           true,
+			},
+			"api_token_file": schema.StringAttribute{
+				MarkdownDescription: "Read the API key from this file, instead of api_token. The file is read on every run, so a rotated key is picked up. Can also be set with the DOG_API_TOKEN_FILE environment variable.",
+				Optional:            true,
+			},
+			"api_token_command": schema.StringAttribute{
+				MarkdownDescription: "Run this command with the system shell and use its output as the API key, instead of api_token. Can also be set with the DOG_API_TOKEN_COMMAND environment variable.",
+				Optional:            true,
+			},
+			"api_token_command_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long api_token_command may run, as a duration such as 30s. Defaults to 30s. Can also be set with the DOG_API_TOKEN_COMMAND_TIMEOUT environment variable.",
+				Optional:            true,
+			},
+			"cache_list_responses": schema.BoolAttribute{
+				MarkdownDescription: "Reuse the responses of list calls, such as the host list read by every dog_host data source, until an object of the same type is changed. Defaults to false.",
+				Optional:            true,
 			},
+			"audit_log_path": schema.StringAttribute{
+				MarkdownDescription: "Append a JSON line to this file for every create, update and delete sent to dog, with link passwords redacted.",
+				Optional:            true,
+			},
+			"skip_version_check": schema.BoolAttribute{
+				MarkdownDescription: "Don't contact dog during configuration to check that it is reachable, accepts the API key and is at least version " + minimumDogVersion + ". Defaults to false.",
+				Optional:            true,
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Refuse to create, update or delete anything in dog, for plans run with production credentials. Data sources keep working. Can also be set with the DOG_READ_ONLY environment variable.",
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"policy": policyBlock(),
 		},
 	}
 }
@@ -72,6 +127,14 @@ func (p *dogProvider) Configure(ctx context.Context, req provider.ConfigureReque
 	// If practitioner provided a configuration value for any of the
 	// attributes, it must be a known value.
 
+	if config.Profile.IsUnknown() {
+		resp.Diagnostics.AddError(
+			"Unknown Dog Profile",
+			"The provider cannot create the Dog API client as there is an unknown configuration value for the profile. "+
+				"Set the value statically in the configuration, or use the DOG_PROFILE environment variable.",
+		)
+	}
+
 	if config.API_Endpoint.IsUnknown() {
 		resp.Diagnostics.AddError(
 			"Unknown Dog API Endpoint",
@@ -88,32 +151,110 @@ func (p *dogProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		)
 	}
 
+	if config.Api_Token_File.IsUnknown() || config.Api_Token_Command.IsUnknown() || config.Api_Token_Command_Timeout.IsUnknown() {
+		resp.Diagnostics.AddError(
+			"Unknown Dog API Key Source",
+			"The provider cannot read the Dog API key as there is an unknown configuration value for api_token_file, api_token_command or api_token_command_timeout. "+
+				"Set the value statically in the configuration, or use the DOG_API_TOKEN_FILE or DOG_API_TOKEN_COMMAND environment variables.",
+		)
+	}
+
+	if config.Read_Only.IsUnknown() {
+		resp.Diagnostics.AddError(
+			"Unknown Dog Read Only Mode",
+			"The provider cannot tell whether it may change dog as there is an unknown configuration value for read_only. "+
+				"Set the value statically in the configuration, or use the DOG_READ_ONLY environment variable.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Default values to environment variables, but override
-	// with Terraform configuration value if set.
+	// Default values to environment variables, override them with the
+	// profile if one is selected, and with Terraform configuration value
+	// if set.
+
+	profile_name := os.Getenv("DOG_PROFILE")
+	if !config.Profile.IsNull() {
+		profile_name = config.Profile.ValueString()
+	}
+
+	var profile connectionProfile
+	if profile_name != "" {
+		var err error
+		if profile, err = loadConnectionProfile(profile_name); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Read Dog Profile",
+				fmt.Sprintf("The provider cannot read the dog profile %q: %s", profile_name, err),
+			)
+			return
+		}
+	}
 
 	api_endpoint := os.Getenv("DOG_API_ENDPOINT")
-	api_token := os.Getenv("DOG_API_TOKEN")
+
+	if profile.API_Endpoint != "" {
+		api_endpoint = profile.API_Endpoint
+	}
 
 	if !config.API_Endpoint.IsNull() {
 		api_endpoint = config.API_Endpoint.ValueString()
 	}
 
-	if !config.Api_Token.IsNull() {
-		api_token = config.Api_Token.ValueString()
+	// The API key is read from the first of api_token, api_token_file and
+	// api_token_command set in the configuration, otherwise from the
+	// profile's, otherwise from the first of the matching environment
+	// variables.
+	token_source, err := envTokenSource()
+	if err == nil {
+		err = profile.applyTokenSource(&token_source)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Dog API Key Source", err.Error())
+		return
+	}
+
+	if !config.Api_Token_Command_Timeout.IsNull() {
+		v := config.Api_Token_Command_Timeout.ValueString()
+		if token_source.timeout, err = time.ParseDuration(v); err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid Dog API Key Source",
+				fmt.Sprintf("api_token_command_timeout must be a duration such as 30s, got: %q", v),
+			)
+			return
+		}
+	}
+
+	config_sources := 0
+	for _, v := range []types.String{config.Api_Token, config.Api_Token_File, config.Api_Token_Command} {
+		if !v.IsNull() {
+			config_sources++
+		}
+	}
+	if config_sources > 1 {
+		resp.Diagnostics.AddError(
+			"Conflicting Dog API Key Sources",
+			"Only one of api_token, api_token_file and api_token_command can be set.",
+		)
+		return
+	}
+	if config_sources == 1 {
+		token_source = tokenSource{
+			token:   config.Api_Token.ValueString(),
+			file:    config.Api_Token_File.ValueString(),
+			command: config.Api_Token_Command.ValueString(),
+			timeout: token_source.timeout,
+		}
 	}
 
-	if api_endpoint == "" || api_token == "" {
+	api_token, err := token_source.resolve(ctx)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"config values",
-			fmt.Sprintf("config.Api_Token: %+v\n", config.Api_Token.ValueString())+
-				fmt.Sprintf("config.API_Endpoint: %+v\n", config.API_Endpoint.ValueString())+
-				fmt.Sprintf("api_endpoint: %+v\n", api_endpoint)+
-				fmt.Sprintf("api_token: %+v\n", api_token),
+			"Unable to Read Dog API Key",
+			fmt.Sprintf("The provider cannot read the Dog API key: %s", err),
 		)
+		return
 	}
 
 	// If any of the expected configurations are missing, return
@@ -123,7 +264,7 @@ func (p *dogProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		resp.Diagnostics.AddError(
 			"Missing Dog API Endpoint",
 			"The provider cannot create the Dog API client as there is a missing or empty value for the Dog API endpoint. "+
-				"Set the API Endpoint value in the configuration or use the DOG_API_ENDPOINT environment variable. "+
+				"Set the API Endpoint value in the configuration or in the selected profile, or use the DOG_API_ENDPOINT environment variable. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
@@ -132,26 +273,85 @@ func (p *dogProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		resp.Diagnostics.AddError(
 			"Missing Dog API Key",
 			"The provider cannot create the Dog API client as there is a missing or empty value for the Dog API key. "+
-				"Set the API Key value in the configuration or use the DOG_API_TOKEN environment variable. "+
+				"Set api_token, api_token_file or api_token_command in the configuration, or use the DOG_API_TOKEN, DOG_API_TOKEN_FILE or DOG_API_TOKEN_COMMAND environment variables. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
 
+	read_only := false
+	if v := os.Getenv("DOG_READ_ONLY"); v != "" {
+		if read_only, err = strconv.ParseBool(v); err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid DOG_READ_ONLY",
+				fmt.Sprintf("DOG_READ_ONLY must be true or false, got: %q", v),
+			)
+		}
+	}
+
+	if !config.Read_Only.IsNull() {
+		read_only = config.Read_Only.ValueBool()
+	}
+
+	var audit_log *auditLog
+	if path := config.Audit_Log_Path.ValueString(); path != "" {
+		if audit_log, err = newAuditLog(path); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Open Audit Log",
+				fmt.Sprintf("The provider cannot write the audit log to %s: %s", path, err),
+			)
+		}
+	}
+
+	policies := newRulesetPolicies(ctx, config.Policy, &resp.Diagnostics)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	c := api.NewClient(api_token, api_endpoint)
+	if err := profile.applyTLS(c); err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Dog Profile TLS Settings",
+			fmt.Sprintf("The provider cannot use the TLS settings of the dog profile %q: %s", profile_name, err),
+		)
+		return
+	}
+	server := newDogServer(nil)
+	if !config.Skip_Version_Check.ValueBool() {
+		server = probeServer(ctx, c, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if config.Cache_List_Responses.ValueBool() {
+		c.SetTransport(newListCache(c.GetClient().Transport))
+	}
+	if read_only {
+		c.SetTransport(readOnlyTransport{c.GetClient().Transport})
+	}
 
 	p.configured = true
-	log.Printf("p.dog: %+v\n", p.dog)
-	log.Printf("p.configured: %+v\n", p.configured)
-	log.Printf("p.version: %+v\n", p.version)
-
 	p.dog = c
+	p.apiToken = api_token
+	p.readOnly = read_only
+	p.auditLog = audit_log
+	p.server = server
+	p.policies = policies
 
-	resp.DataSourceData = p.dog
-	resp.ResourceData = p.dog
+	ctx = p.logContext(ctx, "")
+	tflog.Debug(ctx, "configured dog provider", map[string]any{
+		"dog_api_endpoint":     api_endpoint,
+		"profile":              profile_name,
+		"read_only":            read_only,
+		"cache_list_responses": config.Cache_List_Responses.ValueBool(),
+		"version":              p.version,
+		"dog_version":          server.versionString(),
+		"dog_features":         server.features,
+		"policies":             len(policies),
+	})
+
+	resp.DataSourceData = p
+	resp.ResourceData = p
 }
 
 func (*dogProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -177,5 +377,16 @@ func (*dogProvider) DataSources(ctx context.Context) []func() datasource.DataSou
 		NewProfileDataSource,
 		NewRulesetDataSource,
 		NewFactDataSource,
+		NewServerDataSource,
+		NewReferencesDataSource,
+		NewRulesetDocumentDataSource,
+	}
+}
+
+func (*dogProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewNormalizeCidrsFunction,
+		NewParsePortsFunction,
+		NewTerraformNameFunction,
 	}
 }
@@ -0,0 +1,38 @@
+package dog
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// writable reports whether the provider may change dog. When it is read only,
+// an error naming the refused action is added to diags.
+func (p *dogProvider) writable(diags *diag.Diagnostics, action string) bool {
+	if p.readOnly {
+		diags.AddError(
+			"Provider Is Read Only",
+			fmt.Sprintf("Refusing to %s: the dog provider is configured with read_only, or DOG_READ_ONLY is set.", action),
+		)
+		return false
+	}
+	return true
+}
+
+// readOnlyTransport refuses every request that could change dog, so that a
+// read only provider can't reach the API with one even by mistake.
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, fmt.Errorf("dog provider is read only, refusing %s %s", req.Method, req.URL.Path)
+	}
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
+}
@@ -0,0 +1,117 @@
+package dog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const redacted = "REDACTED"
+
+// apiSubsystem is the tflog subsystem of the requests sent to dog and their
+// responses, shown with TF_LOG_PROVIDER_DOG_API.
+const apiSubsystem = "api"
+
+// sensitiveKeys are the JSON keys whose values are never written outside of
+// dog: tokens, the password of a link's broker connection and its key
+// material.
+var sensitiveKeys = []string{
+	"api_token",
+	"token",
+	"secret",
+	"password",
+	"cacertfile",
+	"certfile",
+	"keyfile",
+}
+
+// sensitiveRe matches a sensitive key and its value in messages formatted as
+// JSON ("password": "x") or with %+v (Password:x).
+var sensitiveRe = regexp.MustCompile(`(?i)"?\b(api_?token|token|secret|password|cacertfile|certfile|keyfile)\b"?\s*[:=]\s*("(?:[^"\\]|\\.)*"|[^\s,}]*)`)
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns a copy of v, as decoded JSON, with the values of sensitive
+// keys replaced.
+func redact(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return redactValue(decoded), nil
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for key, item := range val {
+			if isSensitiveKey(key) {
+				val[key] = redacted
+			} else {
+				val[key] = redactValue(item)
+			}
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = redactValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// logValue is redact for log fields, where a value that can't be redacted is
+// replaced by its error. Log the API structs sent to and read from dog: the
+// framework's types.String and friends have no exported fields, so plans and
+// states would be logged as {}.
+func logValue(v any) any {
+	value, err := redact(v)
+	if err != nil {
+		return fmt.Sprintf("unable to redact: %s", err)
+	}
+	return value
+}
+
+// logContext adds the dog resource type to every log entry and masks the
+// API token and sensitive values, in the provider's logs and in the api
+// subsystem.
+func (p *dogProvider) logContext(ctx context.Context, resourceType string) context.Context {
+	ctx = tflog.SetField(ctx, "dog_resource_type", resourceType)
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, sensitiveKeys...)
+	ctx = tflog.MaskMessageRegexes(ctx, sensitiveRe)
+	ctx = tflog.NewSubsystem(ctx, apiSubsystem)
+	ctx = tflog.SubsystemSetField(ctx, apiSubsystem, "dog_resource_type", resourceType)
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, apiSubsystem, sensitiveKeys...)
+	ctx = tflog.SubsystemMaskMessageRegexes(ctx, apiSubsystem, sensitiveRe)
+	if p.apiToken != "" {
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, p.apiToken)
+		ctx = tflog.MaskMessageStrings(ctx, p.apiToken)
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, apiSubsystem, p.apiToken)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, apiSubsystem, p.apiToken)
+	}
+	return ctx
+}
+
+// logResponse logs an object returned by dog in the api subsystem, with its
+// sensitive values redacted.
+func logResponse(ctx context.Context, message string, v any) {
+	tflog.SubsystemTrace(ctx, apiSubsystem, message, map[string]any{"response": logValue(v)})
+}
@@ -0,0 +1,174 @@
+package dog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	api "github.com/relaypro-open/dog_api_golang/api"
+)
+
+// referencedTypes are the dog types other objects refer to.
+var referencedTypes = []string{"group", "profile", "service", "zone"}
+
+// dogReference is an object that refers to a zone, service, group or
+// profile. Direction, RuleIndex and Comment are only set for ruleset rules,
+// RuleIndex counting from 0 in the inbound or outbound list.
+type dogReference struct {
+	Type      string
+	ID        string
+	Name      string
+	Direction string
+	RuleIndex int
+	Comment   string
+}
+
+func (r dogReference) String() string {
+	if r.Type == "ruleset" {
+		return fmt.Sprintf("ruleset %q (ID %s) %s rule %d", r.Name, r.ID, r.Direction, r.RuleIndex)
+	}
+	return fmt.Sprintf("%s %q (ID %s)", r.Type, r.Name, r.ID)
+}
+
+// findReferences lists the ruleset rules, groups and hosts that refer to the
+// object of the given type, ID and name, from the list endpoints:
+//   - rules refer to zones and groups in group, and to services in service
+//   - groups and rulesets refer to profiles in profile_id
+//   - hosts refer to groups by name in group
+func (p *dogProvider) findReferences(objectType string, id string, name string) ([]dogReference, error) {
+	references := []dogReference{}
+	if objectType != "profile" {
+		rulesets, statusCode, err := p.dog.GetRulesets(nil)
+		if err := listError("rulesets", statusCode, err); err != nil {
+			return nil, err
+		}
+		for _, ruleset := range rulesets {
+			if ruleset.Rules == nil {
+				continue
+			}
+			for _, direction := range []struct {
+				name  string
+				rules []*api.Rule
+			}{{"inbound", ruleset.Rules.Inbound}, {"outbound", ruleset.Rules.Outbound}} {
+				for i, rule := range direction.rules {
+					if rule != nil && ruleRefers(rule, objectType, id) {
+						references = append(references, dogReference{
+							Type:      "ruleset",
+							ID:        ruleset.ID,
+							Name:      ruleset.Name,
+							Direction: direction.name,
+							RuleIndex: i,
+							Comment:   rule.Comment,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	switch objectType {
+	case "group":
+		hosts, statusCode, err := p.dog.GetHostsEncode(nil)
+		if err := listError("hosts", statusCode, err); err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			if host.Group == name {
+				references = append(references, dogReference{Type: "host", ID: host.ID, Name: host.Name})
+			}
+		}
+	case "profile":
+		groups, statusCode, err := p.dog.GetGroupsEncode(nil)
+		if err := listError("groups", statusCode, err); err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			if group.ProfileId == id {
+				references = append(references, dogReference{Type: "group", ID: group.ID, Name: group.Name})
+			}
+		}
+		rulesets, statusCode, err := p.dog.GetRulesets(nil)
+		if err := listError("rulesets", statusCode, err); err != nil {
+			return nil, err
+		}
+		for _, ruleset := range rulesets {
+			if ruleset.ProfileId != nil && *ruleset.ProfileId == id {
+				references = append(references, dogReference{Type: "ruleset", ID: ruleset.ID, Name: ruleset.Name})
+			}
+		}
+	}
+	return references, nil
+}
+
+// ruleRefers reports whether a ruleset rule refers to the object. "any" and
+// "all-active" are never object IDs.
+func ruleRefers(rule *api.Rule, objectType string, id string) bool {
+	switch objectType {
+	case "zone":
+		return rule.GroupType == "ZONE" && rule.Group == id
+	case "group":
+		return rule.GroupType == "ROLE" && rule.Group == id
+	case "service":
+		return rule.Service == id
+	}
+	return false
+}
+
+func listError(list string, statusCode int, err error) error {
+	if err != nil {
+		return fmt.Errorf("unable to list %s, got error: %s", list, err)
+	}
+	if statusCode != 200 {
+		return fmt.Errorf("unable to list %s, got status code: %d", list, statusCode)
+	}
+	return nil
+}
+
+// forceDeleteAttribute is the force_delete attribute of the resources whose
+// Delete first checks that nothing refers to them. Like deletion_protection,
+// it is only kept in the Terraform state.
+func forceDeleteAttribute(resourceType string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		MarkdownDescription: fmt.Sprintf("Delete this %s even when rulesets, groups or hosts still refer to it. "+
+			"The value of the last apply is used, so set it and apply before removing the resource. Defaults to false.", resourceType),
+		Optional: true,
+	}
+}
+
+// unreferenced checks that no ruleset rule, group or host refers to the object
+// before it is deleted, unless forceDelete is set. Otherwise it adds an error
+// listing the referencing objects and returns false.
+//
+// The check is made in Delete rather than when planning: a ruleset that is
+// destroyed or changed in the same apply is handled by Terraform before the
+// object it refers to, and only then are its references gone.
+func (p *dogProvider) unreferenced(diags *diag.Diagnostics, resourceType string, objectType string, id string, name string, forceDelete types.Bool) bool {
+	if forceDelete.ValueBool() {
+		return true
+	}
+	references, err := p.findReferences(objectType, id, name)
+	if err != nil {
+		diags.AddError(
+			"Unable to Check References",
+			fmt.Sprintf("Unable to check what refers to %s %q before deleting it: %s. "+
+				"Set force_delete = true and apply to delete it without checking.", resourceType, name, err),
+		)
+		return false
+	}
+	if len(references) == 0 {
+		return true
+	}
+	lines := make([]string, 0, len(references))
+	for _, reference := range references {
+		lines = append(lines, "  - "+reference.String())
+	}
+	diags.AddError(
+		fmt.Sprintf("%s Still In Use", strings.ToUpper(objectType[:1])+objectType[1:]),
+		fmt.Sprintf("Refusing to delete %s %q (ID %s), it is referred to by:\n%s\n\n"+
+			"Remove the references first, or set force_delete = true and apply before deleting it.",
+			resourceType, name, id, strings.Join(lines, "\n")),
+	)
+	return false
+}
@@ -4,11 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"reflect"
 	"regexp"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -20,6 +17,13 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// factResourceState is a Fact with the deletion_protection that is only kept in
+// the state of a dog_fact.
+type factResourceState struct {
+	Fact
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+}
+
 type (
 	factResource struct {
 		p dogProvider
@@ -44,6 +48,7 @@ func (*factResource) Schema(ctx context.Context, req resource.SchemaRequest, res
 		// This description is used by the documentation generator and the language server.
 
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_fact"),
 			"groups": schema.MapNestedAttribute{
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
@@ -92,17 +97,17 @@ func (r *factResource) Configure(ctx context.Context, req resource.ConfigureRequ
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*factResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -193,9 +198,14 @@ func ApiToFact(fact api.Fact) Fact {
 }
 
 func (r *factResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Fact
+	ctx = r.p.logContext(ctx, "dog_fact")
+	if !r.p.writable(&resp.Diagnostics, "create dog_fact") {
+		return
+	}
 
-	var plan Fact
+	var state factResourceState
+
+	var plan factResourceState
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	//	resp.Diagnostics.AddError("Client Error", fmt.Sprintf("client: %+v\n", r.provider.client))
@@ -203,11 +213,11 @@ func (r *factResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	tflog.Debug(ctx, spew.Sprint("ZZZfact plan: %#v", plan))
-	newFact := FactToApiFact(plan)
-	tflog.Debug(ctx, spew.Sprint("ZZZfact newFact: %#v", newFact))
+	newFact := FactToApiFact(plan.Fact)
+	tflog.Debug(ctx, "fact create request", map[string]any{"request": logValue(newFact)})
 	fact, statusCode, err := r.p.dog.CreateFactEncode(newFact, nil)
-	tflog.Debug(ctx, spew.Sprint("ZZZfact fact: %#v", fact))
+	r.p.audit(&resp.Diagnostics, "dog_fact", "create", fact.ID, plan.Name.ValueString(), statusCode, newFact)
+	logResponse(ctx, "fact", fact)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create fact, got error: %s", err))
 	}
@@ -217,8 +227,7 @@ func (r *factResource) Create(ctx context.Context, req resource.CreateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToFact(fact)
-	tflog.Debug(ctx, spew.Sprint("ZZZfact state: %#v", state))
+	state = factResourceState{Fact: ApiToFact(fact), DeletionProtection: plan.DeletionProtection}
 
 	plan.ID = state.ID
 
@@ -232,7 +241,8 @@ func (r *factResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *factResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Fact
+	ctx = r.p.logContext(ctx, "dog_fact")
+	var state factResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -243,8 +253,6 @@ func (r *factResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 	factID := state.ID.ValueString()
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	fact, statusCode, err := r.p.dog.GetFactEncode(factID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccessful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -255,13 +263,18 @@ func (r *factResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToFact(fact)
+	state = factResourceState{Fact: ApiToFact(fact), DeletionProtection: state.DeletionProtection}
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *factResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Fact
+	ctx = r.p.logContext(ctx, "dog_fact")
+	if !r.p.writable(&resp.Diagnostics, "update dog_fact") {
+		return
+	}
+
+	var state factResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -272,18 +285,18 @@ func (r *factResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	factID := state.ID.ValueString()
 
-	var plan Fact
+	var plan factResourceState
 	diags = req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	newFact := FactToApiFact(plan)
+	newFact := FactToApiFact(plan.Fact)
 	fact, statusCode, err := r.p.dog.UpdateFactEncode(factID, newFact, nil)
-	log.Printf("fact: %+v\n", fact)
-	tflog.Trace(ctx, fmt.Sprintf("fact: %+v\n", fact))
-	state = ApiToFact(fact)
+	r.p.audit(&resp.Diagnostics, "dog_fact", "update", factID, plan.Name.ValueString(), statusCode, newFact)
+	logResponse(ctx, "fact", fact)
+	state = factResourceState{Fact: ApiToFact(fact), DeletionProtection: plan.DeletionProtection}
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create fact, got error: %s", err))
 	}
@@ -308,7 +321,12 @@ func (r *factResource) Update(ctx context.Context, req resource.UpdateRequest, r
 }
 
 func (r *factResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Fact
+	ctx = r.p.logContext(ctx, "dog_fact")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_fact") {
+		return
+	}
+
+	var state factResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -317,8 +335,13 @@ func (r *factResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_fact", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	factID := state.ID.ValueString()
 	fact, statusCode, err := r.p.dog.DeleteFact(factID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_fact", "delete", factID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccessful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -328,7 +351,7 @@ func (r *factResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("fact deleted: %+v\n", fact))
+	logResponse(ctx, "fact deleted", fact)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -352,9 +375,10 @@ type FactGroupModelV0 struct {
 }
 
 type factResourceModelV1 struct {
-	ID     types.String                 `tfsdk:"id"`
-	Groups map[string]*FactGroupModelV1 `tfsdk:"groups"`
-	Name   string                       `tfsdk:"name"`
+	ID                 types.String                 `tfsdk:"id"`
+	Groups             map[string]*FactGroupModelV1 `tfsdk:"groups"`
+	Name               string                       `tfsdk:"name"`
+	DeletionProtection types.Bool                   `tfsdk:"deletion_protection"`
 }
 
 type FactGroupModelV1 struct {
@@ -420,8 +444,7 @@ func (r *factResource) UpgradeState(ctx context.Context) map[int64]resource.Stat
 				for name, group := range priorStateData.Groups {
 					responseVars, _ := json.Marshal(group.Hosts)
 					hostsString := string(responseVars)
-					//fmt.Printf("variable val=%v is of type %v \n", row.AlertEnable, reflect.ValueOf(row.AlertEnable).Kind())
-					tflog.Debug(ctx, fmt.Sprintf("variable val=%v is of type %v \n", hostsString, reflect.ValueOf(hostsString).Kind()))
+					tflog.Debug(ctx, "upgrading fact group hosts to a JSON string", map[string]any{"group": name, "hosts": hostsString})
 					if group.Hosts != nil {
 						g := FactGroupModelV1{
 							Vars:     group.Vars,
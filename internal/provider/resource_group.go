@@ -3,7 +3,6 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
@@ -17,6 +16,14 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// groupResourceState is a Group with the deletion_protection and force_delete
+// that are only kept in the state of a dog_group.
+type groupResourceState struct {
+	Group
+	DeletionProtection  types.Bool                         `tfsdk:"deletion_protection"`
+	ForceDelete         types.Bool                         `tfsdk:"force_delete"`
+}
+
 type (
 	groupResource struct {
 		p dogProvider
@@ -41,6 +48,8 @@ func (*groupResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_group"),
+			"force_delete":        forceDeleteAttribute("dog_group"),
 			// This description is used by the documentation generator and the language server.
 			"description": schema.StringAttribute{
 				MarkdownDescription: "group description",
@@ -123,17 +132,17 @@ func (r *groupResource) Configure(ctx context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *dog.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*groupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -331,9 +340,14 @@ func GroupToUpdateRequest(plan groupResourceData) api.GroupUpdateRequest {
 }
 
 func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Group
+	ctx = r.p.logContext(ctx, "dog_group")
+	if !r.p.writable(&resp.Diagnostics, "create dog_group") {
+		return
+	}
 
-	var plan Group
+	var state groupResourceState
+
+	var plan groupResourceState
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	//	resp.Diagnostics.AddError("Client Error", fmt.Sprintf("client: %+v\n", r.provider.client))
@@ -341,13 +355,11 @@ func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	tflog.Debug(ctx, PrettyFmt("group create plan", plan))
-	newGroup := GroupToApiGroup(plan)
-	tflog.Debug(ctx, PrettyFmt("group create newGroup", newGroup))
+	newGroup := GroupToApiGroup(plan.Group)
+	tflog.Debug(ctx, "group create request", map[string]any{"request": logValue(newGroup)})
 	group, statusCode, err := r.p.dog.CreateGroupEncode(newGroup, nil)
-	tflog.Debug(ctx, PrettyFmt("group create group", group))
-	log.Printf("group: %+v\n", group)
-	tflog.Trace(ctx, fmt.Sprintf("group: %+v\n", group))
+	r.p.audit(&resp.Diagnostics, "dog_group", "create", group.ID, plan.Name.ValueString(), statusCode, newGroup)
+	logResponse(ctx, "group", group)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create group, got error: %s", err))
 	}
@@ -357,8 +369,7 @@ func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToGroup(group)
-	tflog.Debug(ctx, PrettyFmt("group create state", state))
+	state = groupResourceState{Group: ApiToGroup(group), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 
 	plan.ID = state.ID
 
@@ -372,7 +383,8 @@ func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest,
 }
 
 func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Group
+	ctx = r.p.logContext(ctx, "dog_group")
+	var state groupResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -393,13 +405,18 @@ func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToGroup(group)
+	state = groupResourceState{Group: ApiToGroup(group), DeletionProtection: state.DeletionProtection, ForceDelete: state.ForceDelete}
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Group
+	ctx = r.p.logContext(ctx, "dog_group")
+	if !r.p.writable(&resp.Diagnostics, "update dog_group") {
+		return
+	}
+
+	var state groupResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -410,18 +427,18 @@ func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest,
 
 	groupID := state.ID.ValueString()
 
-	var plan Group
+	var plan groupResourceState
 	diags = req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	newGroup := GroupToApiGroup(plan)
+	newGroup := GroupToApiGroup(plan.Group)
 	group, statusCode, err := r.p.dog.UpdateGroupEncode(groupID, newGroup, nil)
-	log.Printf("group: %+v\n", group)
-	tflog.Trace(ctx, fmt.Sprintf("group: %+v\n", group))
-	state = ApiToGroup(group)
+	r.p.audit(&resp.Diagnostics, "dog_group", "update", groupID, plan.Name.ValueString(), statusCode, newGroup)
+	logResponse(ctx, "group", group)
+	state = groupResourceState{Group: ApiToGroup(group), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create group, got error: %s", err))
 	}
@@ -446,7 +463,12 @@ func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest,
 }
 
 func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Group
+	ctx = r.p.logContext(ctx, "dog_group")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_group") {
+		return
+	}
+
+	var state groupResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -455,8 +477,16 @@ func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_group", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	groupID := state.ID.ValueString()
+	if !r.p.unreferenced(&resp.Diagnostics, "dog_group", "group", groupID, state.Name.ValueString(), state.ForceDelete) {
+		return
+	}
 	group, statusCode, err := r.p.dog.DeleteGroup(groupID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_group", "delete", groupID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -466,7 +496,7 @@ func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("group deleted: %+v\n", group))
+	logResponse(ctx, "group deleted", group)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -494,6 +524,7 @@ type groupResourceModelV1 struct {
 	Ec2SecurityGroupIds []*ec2SecurityGroupIdsResourceData `tfsdk:"ec2_security_group_ids"`
 	Vars                *string                            `tfsdk:"vars"`
 	AlertEnable         *bool                              `tfsdk:"alert_enable"`
+	DeletionProtection  types.Bool                         `tfsdk:"deletion_protection"`
 }
 
 func (r *groupResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
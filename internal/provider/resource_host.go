@@ -3,7 +3,6 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
@@ -96,17 +95,17 @@ func (r *hostResource) Configure(ctx context.Context, req resource.ConfigureRequ
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*hostResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -251,6 +250,11 @@ func HostToUpdateRequest(plan hostResourceData) api.HostUpdateRequest {
 }
 
 func (r *hostResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = r.p.logContext(ctx, "dog_host")
+	if !r.p.writable(&resp.Diagnostics, "create dog_host") {
+		return
+	}
+
 	var state Host
 
 	//var plan hostResourceData
@@ -263,10 +267,9 @@ func (r *hostResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	newHost := HostToApiHost(plan)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	host, statusCode, err := r.p.dog.CreateHostEncode(newHost, nil)
-	log.Printf("host: %+v\n", host)
-	tflog.Trace(ctx, fmt.Sprintf("host: %+v\n", host))
+	r.p.audit(&resp.Diagnostics, "dog_host", "create", host.ID, plan.Name.ValueString(), statusCode, newHost)
+	logResponse(ctx, "host", host)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create host, got error: %s", err))
 	}
@@ -290,6 +293,7 @@ func (r *hostResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *hostResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = r.p.logContext(ctx, "dog_host")
 	var state Host
 
 	diags := req.State.Get(ctx, &state)
@@ -301,8 +305,6 @@ func (r *hostResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 	hostID := state.ID.ValueString()
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	host, statusCode, err := r.p.dog.GetHostEncode(hostID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccessful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -319,6 +321,11 @@ func (r *hostResource) Read(ctx context.Context, req resource.ReadRequest, resp
 }
 
 func (r *hostResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = r.p.logContext(ctx, "dog_host")
+	if !r.p.writable(&resp.Diagnostics, "update dog_host") {
+		return
+	}
+
 	var state Host
 
 	diags := req.State.Get(ctx, &state)
@@ -339,8 +346,8 @@ func (r *hostResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	newHost := HostToApiHost(plan)
 	host, statusCode, err := r.p.dog.UpdateHostEncode(hostID, newHost, nil)
-	log.Printf("host: %+v\n", host)
-	tflog.Trace(ctx, fmt.Sprintf("host: %+v\n", host))
+	r.p.audit(&resp.Diagnostics, "dog_host", "update", hostID, plan.Name.ValueString(), statusCode, newHost)
+	logResponse(ctx, "host", host)
 	state = ApiToHost(host)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create host, got error: %s", err))
@@ -366,6 +373,11 @@ func (r *hostResource) Update(ctx context.Context, req resource.UpdateRequest, r
 }
 
 func (r *hostResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = r.p.logContext(ctx, "dog_host")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_host") {
+		return
+	}
+
 	var state Host
 
 	diags := req.State.Get(ctx, &state)
@@ -377,6 +389,7 @@ func (r *hostResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 
 	hostID := state.ID.ValueString()
 	host, statusCode, err := r.p.dog.DeleteHost(hostID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_host", "delete", hostID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccessful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -386,7 +399,7 @@ func (r *hostResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("host deleted: %+v\n", host))
+	logResponse(ctx, "host deleted", host)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -3,11 +3,15 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	api "github.com/relaypro-open/dog_api_golang/api"
@@ -39,6 +43,7 @@ func (*linkResource) Schema(ctx context.Context, req resource.SchemaRequest, res
 		MarkdownDescription: "Link data source",
 
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_link"),
 			// This description is used by the documentation generator and the language server.
 			"address_handling": schema.StringAttribute{
 				MarkdownDescription: "Type of address handling",
@@ -55,8 +60,25 @@ func (*linkResource) Schema(ctx context.Context, req resource.SchemaRequest, res
 						Required: true,
 					},
 					"password": schema.StringAttribute{
-						Required:  true,
-						Sensitive: true,
+						MarkdownDescription: "Broker password, stored in state. Exactly one of password and password_wo must be set.",
+						Optional:            true,
+						Sensitive:           true,
+					},
+					"password_wo": schema.StringAttribute{
+						MarkdownDescription: "Broker password, sent to dog but never stored in state or plan files. Change password_wo_version to send a new password. Needs Terraform 1.11 or later.",
+						Optional:            true,
+						Sensitive:           true,
+						WriteOnly:           true,
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("password")),
+						},
+					},
+					"password_wo_version": schema.Int64Attribute{
+						MarkdownDescription: "Version of password_wo. Changing it updates the link with the current password_wo.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AlsoRequires(path.MatchRelative().AtParent().AtName("password_wo")),
+						},
 					},
 					"port": schema.Int64Attribute{
 						Required: true,
@@ -123,17 +145,17 @@ func (r *linkResource) Configure(ctx context.Context, req resource.ConfigureRequ
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*linkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -148,16 +170,20 @@ type linkResourceData struct {
 	Enabled         types.Bool              `tfsdk:"enabled"`
 	ID              types.String            `tfsdk:"id"`
 	Name            types.String            `tfsdk:"name"`
+	// DeletionProtection is only kept in the Terraform state.
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
 }
 
 type connectionResourceData struct {
-	ApiPort     types.Int64            `tfsdk:"api_port"`
-	Host        types.String           `tfsdk:"host"`
-	Password    types.String           `tfsdk:"password"`
-	Port        types.Int64            `tfsdk:"port"`
-	SSLOptions  *sslOptionsResouceData `tfsdk:"ssl_options"`
-	User        types.String           `tfsdk:"user"`
-	VirtualHost types.String           `tfsdk:"virtual_host"`
+	ApiPort           types.Int64            `tfsdk:"api_port"`
+	Host              types.String           `tfsdk:"host"`
+	Password          types.String           `tfsdk:"password"`
+	PasswordWO        types.String           `tfsdk:"password_wo"`
+	PasswordWOVersion types.Int64            `tfsdk:"password_wo_version"`
+	Port              types.Int64            `tfsdk:"port"`
+	SSLOptions        *sslOptionsResouceData `tfsdk:"ssl_options"`
+	User              types.String           `tfsdk:"user"`
+	VirtualHost       types.String           `tfsdk:"virtual_host"`
 }
 
 type sslOptionsResouceData struct {
@@ -251,8 +277,64 @@ func ApiToLink(link api.Link) Link {
 	return newLink
 }
 
+// ApiToLinkResource returns the state of a link read from dog. A link whose
+// password is given with password_wo keeps it out of the state, whatever dog
+// returns, along with the version it was last sent with.
+func ApiToLinkResource(link api.Link, writeOnly bool, passwordVersion types.Int64) linkResourceData {
+	l := ApiToLink(link)
+	state := linkResourceData{
+		AddressHandling: l.AddressHandling,
+		Connection: &connectionResourceData{
+			ApiPort:           l.Connection.ApiPort,
+			Host:              l.Connection.Host,
+			Password:          l.Connection.Password,
+			PasswordWO:        types.StringNull(),
+			PasswordWOVersion: types.Int64Null(),
+			Port:              l.Connection.Port,
+			SSLOptions: &sslOptionsResouceData{
+				CaCertFile:           l.Connection.SSLOptions.CaCertFile,
+				CertFile:             l.Connection.SSLOptions.CertFile,
+				FailIfNoPeerCert:     l.Connection.SSLOptions.FailIfNoPeerCert,
+				KeyFile:              l.Connection.SSLOptions.KeyFile,
+				ServerNameIndication: l.Connection.SSLOptions.ServerNameIndication,
+				Verify:               l.Connection.SSLOptions.Verify,
+			},
+			User:        l.Connection.User,
+			VirtualHost: l.Connection.VirtualHost,
+		},
+		ConnectionType: l.ConnectionType,
+		Direction:      l.Direction,
+		Enabled:        l.Enabled,
+		ID:             l.ID,
+		Name:           l.Name,
+	}
+	if writeOnly {
+		state.Connection.Password = types.StringNull()
+		state.Connection.PasswordWOVersion = passwordVersion
+	}
+	return state
+}
+
+// usesWriteOnlyPassword returns whether the link's password is given with
+// password_wo, whose value is only in the configuration, never in the plan.
+// It is copied to the plan's password for the request to dog.
+func usesWriteOnlyPassword(ctx context.Context, config tfsdk.Config, plan *linkResourceData, diags *diag.Diagnostics) bool {
+	if plan.Connection == nil || !plan.Connection.Password.IsNull() {
+		return false
+	}
+	var password types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("dog_connection").AtName("password_wo"), &password)...)
+	plan.Connection.Password = password
+	return true
+}
+
 func (r *linkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Link
+	ctx = r.p.logContext(ctx, "dog_link")
+	if !r.p.writable(&resp.Diagnostics, "create dog_link") {
+		return
+	}
+
+	var state linkResourceData
 
 	var plan linkResourceData
 	diags := req.Plan.Get(ctx, &plan)
@@ -261,12 +343,15 @@ func (r *linkResource) Create(ctx context.Context, req resource.CreateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	writeOnly := usesWriteOnlyPassword(ctx, req.Config, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	newLink := LinkToCreateRequest(plan)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	link, statusCode, err := r.p.dog.CreateLink(newLink, nil)
-	log.Printf("link: %+v\n", link)
-	tflog.Trace(ctx, fmt.Sprintf("link: %+v\n", link))
+	r.p.audit(&resp.Diagnostics, "dog_link", "create", link.ID, plan.Name.ValueString(), statusCode, newLink)
+	logResponse(ctx, "link", link)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create link, got error: %s", err))
 	}
@@ -276,7 +361,8 @@ func (r *linkResource) Create(ctx context.Context, req resource.CreateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToLink(link)
+	state = ApiToLinkResource(link, writeOnly, plan.Connection.PasswordWOVersion)
+	state.DeletionProtection = plan.DeletionProtection
 
 	plan.ID = state.ID
 
@@ -290,7 +376,8 @@ func (r *linkResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *linkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Link
+	ctx = r.p.logContext(ctx, "dog_link")
+	var state linkResourceData
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -300,9 +387,14 @@ func (r *linkResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	linkID := state.ID.ValueString()
+	// the password of a link imported or created with password is read back
+	// from dog, one given with password_wo never is
+	writeOnly := state.Connection != nil && state.Connection.Password.IsNull()
+	passwordVersion := types.Int64Null()
+	if writeOnly {
+		passwordVersion = state.Connection.PasswordWOVersion
+	}
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	link, statusCode, err := r.p.dog.GetLink(linkID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -313,13 +405,20 @@ func (r *linkResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToLink(link)
+	deletionProtection := state.DeletionProtection
+	state = ApiToLinkResource(link, writeOnly, passwordVersion)
+	state.DeletionProtection = deletionProtection
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *linkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Link
+	ctx = r.p.logContext(ctx, "dog_link")
+	if !r.p.writable(&resp.Diagnostics, "update dog_link") {
+		return
+	}
+
+	var state linkResourceData
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -336,12 +435,17 @@ func (r *linkResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	writeOnly := usesWriteOnlyPassword(ctx, req.Config, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	newLink := LinkToUpdateRequest(plan)
 	link, statusCode, err := r.p.dog.UpdateLink(linkID, newLink, nil)
-	log.Printf("link: %+v\n", link)
-	tflog.Trace(ctx, fmt.Sprintf("link: %+v\n", link))
-	state = ApiToLink(link)
+	r.p.audit(&resp.Diagnostics, "dog_link", "update", linkID, plan.Name.ValueString(), statusCode, newLink)
+	logResponse(ctx, "link", link)
+	state = ApiToLinkResource(link, writeOnly, plan.Connection.PasswordWOVersion)
+	state.DeletionProtection = plan.DeletionProtection
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create link, got error: %s", err))
 	}
@@ -366,7 +470,12 @@ func (r *linkResource) Update(ctx context.Context, req resource.UpdateRequest, r
 }
 
 func (r *linkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Link
+	ctx = r.p.logContext(ctx, "dog_link")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_link") {
+		return
+	}
+
+	var state linkResourceData
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -375,8 +484,13 @@ func (r *linkResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_link", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	linkID := state.ID.ValueString()
 	link, statusCode, err := r.p.dog.DeleteLink(linkID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_link", "delete", linkID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -386,7 +500,7 @@ func (r *linkResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("link deleted: %+v\n", link))
+	logResponse(ctx, "link deleted", link)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
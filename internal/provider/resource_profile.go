@@ -3,7 +3,6 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -17,9 +16,19 @@ import (
 )
 
 type profileResourceData struct {
-	ID      types.String `tfsdk:"id"`
-	Name    string       `tfsdk:"name"`
-	Version string       `tfsdk:"version"`
+	ID                 types.String `tfsdk:"id"`
+	Name               string       `tfsdk:"name"`
+	Version            string       `tfsdk:"version"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	ForceDelete        types.Bool   `tfsdk:"force_delete"`
+}
+
+// profileResourceState is a Profile with the deletion_protection and force_delete
+// that are only kept in the state of a dog_profile.
+type profileResourceState struct {
+	Profile
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+	ForceDelete        types.Bool `tfsdk:"force_delete"`
 }
 
 type (
@@ -47,6 +56,8 @@ func (*profileResource) Schema(ctx context.Context, req resource.SchemaRequest,
 		MarkdownDescription: "Profile data source",
 
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_profile"),
+			"force_delete":        forceDeleteAttribute("dog_profile"),
 			"name": schema.StringAttribute{
 				MarkdownDescription: "Profile name",
 				Optional:            true,
@@ -73,17 +84,17 @@ func (r *profileResource) Configure(ctx context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*profileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -118,7 +129,12 @@ func ApiToProfile(profile api.Profile) Profile {
 }
 
 func (r *profileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Profile
+	ctx = r.p.logContext(ctx, "dog_profile")
+	if !r.p.writable(&resp.Diagnostics, "create dog_profile") {
+		return
+	}
+
+	var state profileResourceState
 
 	var plan profileResourceData
 	diags := req.Plan.Get(ctx, &plan)
@@ -129,10 +145,9 @@ func (r *profileResource) Create(ctx context.Context, req resource.CreateRequest
 	}
 
 	newProfile := ProfileToCreateRequest(plan)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	profile, statusCode, err := r.p.dog.CreateProfile(newProfile, nil)
-	log.Printf("profile: %+v\n", profile)
-	tflog.Trace(ctx, fmt.Sprintf("profile: %+v\n", profile))
+	r.p.audit(&resp.Diagnostics, "dog_profile", "create", profile.ID, plan.Name, statusCode, newProfile)
+	logResponse(ctx, "profile", profile)
 	if statusCode != 201 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -142,7 +157,7 @@ func (r *profileResource) Create(ctx context.Context, req resource.CreateRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToProfile(profile)
+	state = profileResourceState{Profile: ApiToProfile(profile), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 
 	plan.ID = state.ID
 
@@ -156,7 +171,8 @@ func (r *profileResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *profileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Profile
+	ctx = r.p.logContext(ctx, "dog_profile")
+	var state profileResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -167,8 +183,6 @@ func (r *profileResource) Read(ctx context.Context, req resource.ReadRequest, re
 
 	profileID := state.ID.ValueString()
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	profile, statusCode, err := r.p.dog.GetProfile(profileID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -179,13 +193,18 @@ func (r *profileResource) Read(ctx context.Context, req resource.ReadRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToProfile(profile)
+	state = profileResourceState{Profile: ApiToProfile(profile), DeletionProtection: state.DeletionProtection, ForceDelete: state.ForceDelete}
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *profileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Profile
+	ctx = r.p.logContext(ctx, "dog_profile")
+	if !r.p.writable(&resp.Diagnostics, "update dog_profile") {
+		return
+	}
+
+	var state profileResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -205,9 +224,9 @@ func (r *profileResource) Update(ctx context.Context, req resource.UpdateRequest
 
 	newProfile := ProfileToUpdateRequest(plan)
 	profile, statusCode, err := r.p.dog.UpdateProfile(profileID, newProfile, nil)
-	log.Printf("profile: %+v\n", profile)
-	tflog.Trace(ctx, fmt.Sprintf("profile: %+v\n", profile))
-	state = ApiToProfile(profile)
+	r.p.audit(&resp.Diagnostics, "dog_profile", "update", profileID, plan.Name, statusCode, newProfile)
+	logResponse(ctx, "profile", profile)
+	state = profileResourceState{Profile: ApiToProfile(profile), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create profile, got error: %s", err))
 	}
@@ -229,7 +248,12 @@ func (r *profileResource) Update(ctx context.Context, req resource.UpdateRequest
 }
 
 func (r *profileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Profile
+	ctx = r.p.logContext(ctx, "dog_profile")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_profile") {
+		return
+	}
+
+	var state profileResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -238,8 +262,16 @@ func (r *profileResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_profile", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	profileID := state.ID.ValueString()
+	if !r.p.unreferenced(&resp.Diagnostics, "dog_profile", "profile", profileID, state.Name.ValueString(), state.ForceDelete) {
+		return
+	}
 	profile, statusCode, err := r.p.dog.DeleteProfile(profileID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_profile", "delete", profileID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -249,7 +281,7 @@ func (r *profileResource) Delete(ctx context.Context, req resource.DeleteRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("profile deleted: %+v\n", profile))
+	logResponse(ctx, "profile deleted", profile)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
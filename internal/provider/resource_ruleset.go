@@ -3,10 +3,8 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -19,6 +17,15 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// rulesetResourceState is a Ruleset with the deletion_protection that is only kept in
+// the state of a dog_ruleset, and its rules by name when keyed_rules is used instead
+// of rules.
+type rulesetResourceState struct {
+	Ruleset
+	KeyedRules         *rulesetResourceKeyedRules `tfsdk:"keyed_rules"`
+	DeletionProtection types.Bool                 `tfsdk:"deletion_protection"`
+}
+
 type (
 	rulesetResource struct {
 		p dogProvider
@@ -28,6 +35,7 @@ type (
 var (
 	_ resource.Resource                = (*rulesetResource)(nil)
 	_ resource.ResourceWithImportState = (*rulesetResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*rulesetResource)(nil)
 )
 
 func NewRulesetResource() resource.Resource {
@@ -44,6 +52,7 @@ func (*rulesetResource) Schema(ctx context.Context, req resource.SchemaRequest,
 		MarkdownDescription: "Ruleset data source",
 
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_ruleset"),
 			"name": schema.StringAttribute{
 				MarkdownDescription: "ruleset name",
 				Optional:            true,
@@ -62,145 +71,18 @@ func (*rulesetResource) Schema(ctx context.Context, req resource.SchemaRequest,
 					"inbound": schema.ListNestedAttribute{
 						Required: true,
 						NestedObject: schema.NestedAttributeObject{
-							Attributes: map[string]schema.Attribute{
-								"action": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{stringvalidator.OneOf(
-										"ACCEPT",
-										"DROP",
-										"REJECT")},
-								},
-								"active": schema.BoolAttribute{
-									Required: true,
-								},
-								"comment": schema.StringAttribute{
-									Required: true,
-								},
-								"environments": schema.ListAttribute{
-									ElementType: types.StringType,
-									Required:    true,
-								},
-								"group": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{
-										stringvalidator.LengthBetween(1, 37),
-										stringvalidator.RegexMatches(
-											regexp.MustCompile(`^[A-Za-z0-9_.-]*$`), "Must begin with alphanumeric, _, ., -",
-										),
-									},
-								},
-								"group_type": schema.StringAttribute{
-									Required:   true,
-									Validators: []validator.String{stringvalidator.OneOf("ANY", "GROUP", "ROLE", "ZONE")},
-								},
-								"interface": schema.StringAttribute{
-									Required: true,
-								},
-								"log": schema.BoolAttribute{
-									Required: true,
-								},
-								"log_prefix": schema.StringAttribute{
-									Required: true,
-								},
-								"service": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{
-										stringvalidator.LengthAtLeast(1),
-										stringvalidator.RegexMatches(
-											regexp.MustCompile(`^[A-Za-z0-9_.-]*$`), "Must begin with alphanumeric, _, ., -",
-										),
-									},
-								},
-								"states": schema.ListAttribute{
-									ElementType: types.StringType,
-									Required:    true,
-									Validators: []validator.List{
-										listvalidator.ValueStringsAre(stringvalidator.OneOf("NEW", "ESTABLISHED", "RELATED", "INVALID")),
-									},
-								},
-								"type": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{stringvalidator.OneOf(
-										"BASIC",
-										//"CONNLIMIT",  //TODO
-										//"RECENT"      //TODO
-									)},
-								},
-							},
+							Attributes: rulesetRuleAttributes(),
 						},
 					},
 					"outbound": schema.ListNestedAttribute{
 						Required: true,
 						NestedObject: schema.NestedAttributeObject{
-							Attributes: map[string]schema.Attribute{
-								"action": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{stringvalidator.OneOf(
-										"ACCEPT",
-										"DROP",
-										"REJECT")},
-								},
-								"active": schema.BoolAttribute{
-									Required: true,
-								},
-								"comment": schema.StringAttribute{
-									Required: true,
-								},
-								"environments": schema.ListAttribute{
-									ElementType: types.StringType,
-									Required:    true,
-								},
-								"group": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{
-										stringvalidator.LengthBetween(1, 37),
-										stringvalidator.RegexMatches(
-											regexp.MustCompile(`^[A-Za-z0-9_.-]*$`), "Must begin with alphanumeric, _, ., -",
-										),
-									},
-								},
-								"group_type": schema.StringAttribute{
-									Required:   true,
-									Validators: []validator.String{stringvalidator.OneOf("ANY", "GROUP", "ROLE", "ZONE")},
-								},
-								"interface": schema.StringAttribute{
-									Required: true,
-								},
-								"log": schema.BoolAttribute{
-									Required: true,
-								},
-								"log_prefix": schema.StringAttribute{
-									Required: true,
-								},
-								"service": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{
-										stringvalidator.LengthAtLeast(1),
-										stringvalidator.RegexMatches(
-											regexp.MustCompile(`^[A-Za-z0-9_.-]*$`), "Must begin with alphanumeric, _, ., -",
-										),
-									},
-								},
-								"states": schema.ListAttribute{
-									ElementType: types.StringType,
-									Required:    true,
-									Validators: []validator.List{
-										listvalidator.ValueStringsAre(stringvalidator.OneOf("NEW", "ESTABLISHED", "RELATED", "INVALID")),
-									},
-								},
-								"type": schema.StringAttribute{
-									Required: true,
-									Validators: []validator.String{stringvalidator.OneOf(
-										"BASIC",
-										//"CONNLIMIT",  //TODO
-										//"RECENT"      //TODO
-									)},
-								},
-							},
+							Attributes: rulesetRuleAttributes(),
 						},
 					},
 				},
 			},
+			"keyed_rules": keyedRulesAttribute(),
 			"id": schema.StringAttribute{
 				Optional:            true,
 				MarkdownDescription: "Rule identifier",
@@ -210,23 +92,101 @@ func (*rulesetResource) Schema(ctx context.Context, req resource.SchemaRequest,
 	}
 }
 
+// rulesetRuleAttributes are the attributes of a rule of a dog_ruleset, in
+// both the rules lists and the keyed_rules maps.
+func rulesetRuleAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"action": schema.StringAttribute{
+			Required: true,
+			Validators: []validator.String{stringvalidator.OneOf(
+				"ACCEPT",
+				"DROP",
+				"REJECT")},
+		},
+		"active": schema.BoolAttribute{
+			Required: true,
+		},
+		"comment": schema.StringAttribute{
+			Required: true,
+		},
+		"environments": schema.ListAttribute{
+			ElementType: types.StringType,
+			Required:    true,
+		},
+		"group": schema.StringAttribute{
+			Required: true,
+			Validators: []validator.String{
+				stringvalidator.LengthBetween(1, 37),
+				stringvalidator.RegexMatches(
+					regexp.MustCompile(`^[A-Za-z0-9_.-]*$`), "Must begin with alphanumeric, _, ., -",
+				),
+			},
+		},
+		"group_type": schema.StringAttribute{
+			Required:   true,
+			Validators: []validator.String{stringvalidator.OneOf("ANY", "GROUP", "ROLE", "ZONE")},
+		},
+		"interface": schema.StringAttribute{
+			Required: true,
+		},
+		"log": schema.BoolAttribute{
+			Required: true,
+		},
+		"log_prefix": schema.StringAttribute{
+			Required: true,
+		},
+		"service": schema.StringAttribute{
+			Required: true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+				stringvalidator.RegexMatches(
+					regexp.MustCompile(`^[A-Za-z0-9_.-]*$`), "Must begin with alphanumeric, _, ., -",
+				),
+			},
+		},
+		"states": schema.ListAttribute{
+			ElementType: types.StringType,
+			Required:    true,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.OneOf("NEW", "ESTABLISHED", "RELATED", "INVALID")),
+			},
+		},
+		"type": schema.StringAttribute{
+			Required: true,
+			Validators: []validator.String{stringvalidator.OneOf(
+				"BASIC",
+				//"CONNLIMIT",  //TODO
+				//"RECENT"      //TODO
+			)},
+		},
+	}
+}
+
 func (r *rulesetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
+}
+
+// ModifyPlan checks the planned rules against the provider's policies.
+func (r *rulesetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	checkRulesetPolicies(ctx, r.p.policies, req.Plan, &resp.Diagnostics)
 }
 
 func (*rulesetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -234,10 +194,12 @@ func (*rulesetResource) ImportState(ctx context.Context, req resource.ImportStat
 }
 
 type rulesetResourceData struct {
-	ID        types.String          `tfsdk:"id"`
-	Rules     *rulesetResourceRules `tfsdk:"rules"`
-	Name      string                `tfsdk:"name"`
-	ProfileId *string               `tfsdk:"profile_id" force:",omitempty"`
+	ID                 types.String               `tfsdk:"id"`
+	Rules              *rulesetResourceRules      `tfsdk:"rules"`
+	KeyedRules         *rulesetResourceKeyedRules `tfsdk:"keyed_rules"`
+	Name               string                     `tfsdk:"name"`
+	ProfileId          *string                    `tfsdk:"profile_id" force:",omitempty"`
+	DeletionProtection types.Bool                 `tfsdk:"deletion_protection"`
 }
 
 type rulesetResourceRules struct {
@@ -298,7 +260,7 @@ func RulesetToCreateRequest(ctx context.Context, plan rulesetResourceData) api.R
 		outboundRules = append(outboundRules, rule)
 	}
 
-	tflog.Debug(ctx, spew.Sprint("ZZZplan.ProfileId: %#v", plan.ProfileId))
+	tflog.Debug(ctx, "building ruleset create request", map[string]any{"profile_id": logValue(plan.ProfileId)})
 	if plan.ProfileId == nil {
 		newRuleset := api.RulesetCreateRequest{
 			Name: plan.Name,
@@ -307,7 +269,7 @@ func RulesetToCreateRequest(ctx context.Context, plan rulesetResourceData) api.R
 				Outbound: outboundRules,
 			},
 		}
-		tflog.Debug(ctx, spew.Sprint("ZZZnewRuleset: %#v", newRuleset))
+		tflog.Debug(ctx, "ruleset create request", map[string]any{"request": logValue(newRuleset)})
 		return newRuleset
 	} else {
 		newRuleset := api.RulesetCreateRequest{
@@ -363,7 +325,7 @@ func RulesetToUpdateRequest(ctx context.Context, plan rulesetResourceData) api.R
 	newString := "123"
 	newStringPointer := &newString
 
-	tflog.Debug(ctx, spew.Sprint("ZZZplan.ProfileId: %#v", plan.ProfileId))
+	tflog.Debug(ctx, "building ruleset update request", map[string]any{"profile_id": logValue(plan.ProfileId)})
 	if plan.ProfileId == nil {
 		newRuleset := api.RulesetUpdateRequest{
 			Name: plan.Name,
@@ -373,7 +335,7 @@ func RulesetToUpdateRequest(ctx context.Context, plan rulesetResourceData) api.R
 			},
 			ProfileId: newStringPointer,
 		}
-		tflog.Debug(ctx, spew.Sprint("ZZZnewRuleset: %#v", newRuleset))
+		tflog.Debug(ctx, "ruleset update request", map[string]any{"request": logValue(newRuleset)})
 		return newRuleset
 	} else {
 		newRuleset := api.RulesetUpdateRequest{
@@ -426,7 +388,7 @@ func ApiToRuleset(ctx context.Context, ruleset api.Ruleset) Ruleset {
 		newOutboundRules = append(newOutboundRules, rule)
 	}
 
-	tflog.Debug(ctx, spew.Sprint("ZZZruleset: %#v", ruleset))
+	tflog.Debug(ctx, "converting ruleset from dog", map[string]any{"ruleset": logValue(ruleset)})
 	if ruleset.ProfileId == nil {
 		h := Ruleset{
 			ID:   types.StringValue(ruleset.ID),
@@ -437,7 +399,6 @@ func ApiToRuleset(ctx context.Context, ruleset api.Ruleset) Ruleset {
 			},
 			ProfileId: types.StringNull(),
 		}
-		tflog.Debug(ctx, spew.Sprint("ZZZh: %#v", h))
 		return h
 	} else {
 		h := Ruleset{
@@ -454,7 +415,12 @@ func ApiToRuleset(ctx context.Context, ruleset api.Ruleset) Ruleset {
 }
 
 func (r *rulesetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Ruleset
+	ctx = r.p.logContext(ctx, "dog_ruleset")
+	if !r.p.writable(&resp.Diagnostics, "create dog_ruleset") {
+		return
+	}
+
+	var state rulesetResourceState
 
 	var plan rulesetResourceData
 	diags := req.Plan.Get(ctx, &plan)
@@ -464,11 +430,13 @@ func (r *rulesetResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	if plan.KeyedRules != nil {
+		plan.Rules = plan.KeyedRules.rules()
+	}
 	newRuleset := RulesetToCreateRequest(ctx, plan)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	ruleset, statusCode, err := r.p.dog.CreateRuleset(newRuleset, nil)
-	log.Printf("ruleset: %+v\n", ruleset)
-	tflog.Debug(ctx, fmt.Sprintf("ruleset: %+v\n", ruleset))
+	r.p.audit(&resp.Diagnostics, "dog_ruleset", "create", ruleset.ID, plan.Name, statusCode, newRuleset)
+	logResponse(ctx, "ruleset", ruleset)
 	if statusCode != 201 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -478,7 +446,8 @@ func (r *rulesetResource) Create(ctx context.Context, req resource.CreateRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToRuleset(ctx, ruleset)
+	state = rulesetResourceState{Ruleset: ApiToRuleset(ctx, ruleset), DeletionProtection: plan.DeletionProtection}
+	state.useKeyedRules(plan.KeyedRules)
 
 	plan.ID = state.ID
 
@@ -492,7 +461,8 @@ func (r *rulesetResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *rulesetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Ruleset
+	ctx = r.p.logContext(ctx, "dog_ruleset")
+	var state rulesetResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -503,8 +473,6 @@ func (r *rulesetResource) Read(ctx context.Context, req resource.ReadRequest, re
 
 	rulesetID := state.ID.ValueString()
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	ruleset, statusCode, err := r.p.dog.GetRuleset(rulesetID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -515,13 +483,20 @@ func (r *rulesetResource) Read(ctx context.Context, req resource.ReadRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToRuleset(ctx, ruleset)
+	keyedRules := state.KeyedRules
+	state = rulesetResourceState{Ruleset: ApiToRuleset(ctx, ruleset), DeletionProtection: state.DeletionProtection}
+	state.useKeyedRules(keyedRules)
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *rulesetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Ruleset
+	ctx = r.p.logContext(ctx, "dog_ruleset")
+	if !r.p.writable(&resp.Diagnostics, "update dog_ruleset") {
+		return
+	}
+
+	var state rulesetResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -539,11 +514,15 @@ func (r *rulesetResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
+	if plan.KeyedRules != nil {
+		plan.Rules = plan.KeyedRules.rules()
+	}
 	newRuleset := RulesetToUpdateRequest(ctx, plan)
 	ruleset, statusCode, err := r.p.dog.UpdateRuleset(rulesetID, newRuleset, nil)
-	log.Printf("ruleset: %+v\n", ruleset)
-	tflog.Debug(ctx, fmt.Sprintf("ruleset: %+v\n", ruleset))
-	state = ApiToRuleset(ctx, ruleset)
+	r.p.audit(&resp.Diagnostics, "dog_ruleset", "update", rulesetID, plan.Name, statusCode, newRuleset)
+	logResponse(ctx, "ruleset", ruleset)
+	state = rulesetResourceState{Ruleset: ApiToRuleset(ctx, ruleset), DeletionProtection: plan.DeletionProtection}
+	state.useKeyedRules(plan.KeyedRules)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create ruleset, got error: %s", err))
 	}
@@ -565,7 +544,12 @@ func (r *rulesetResource) Update(ctx context.Context, req resource.UpdateRequest
 }
 
 func (r *rulesetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Ruleset
+	ctx = r.p.logContext(ctx, "dog_ruleset")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_ruleset") {
+		return
+	}
+
+	var state rulesetResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -574,8 +558,13 @@ func (r *rulesetResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_ruleset", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	rulesetID := state.ID.ValueString()
 	ruleset, statusCode, err := r.p.dog.DeleteRuleset(rulesetID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_ruleset", "delete", rulesetID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -585,7 +574,7 @@ func (r *rulesetResource) Delete(ctx context.Context, req resource.DeleteRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Debug(ctx, fmt.Sprintf("ruleset deleted: %+v\n", ruleset))
+	logResponse(ctx, "ruleset deleted", ruleset)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -3,7 +3,6 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
@@ -18,6 +17,14 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// serviceResourceState is a Service with the deletion_protection and force_delete
+// that are only kept in the state of a dog_service.
+type serviceResourceState struct {
+	Service
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+	ForceDelete        types.Bool `tfsdk:"force_delete"`
+}
+
 type (
 	serviceResource struct {
 		p dogProvider
@@ -43,6 +50,8 @@ func (*serviceResource) Schema(ctx context.Context, req resource.SchemaRequest,
 		MarkdownDescription: "Service data source",
 
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_service"),
+			"force_delete":        forceDeleteAttribute("dog_service"),
 			// This description is used by the documentation generator and the language server.
 			"services": schema.ListNestedAttribute{
 				MarkdownDescription: "List of Services",
@@ -95,17 +104,17 @@ func (r *serviceResource) Configure(ctx context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*serviceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -113,10 +122,12 @@ func (*serviceResource) ImportState(ctx context.Context, req resource.ImportStat
 }
 
 type serviceResourceData struct {
-	ID       types.String                `tfsdk:"id"`
-	Services []*portProtocolResourceData `tfsdk:"services"`
-	Name     string                      `tfsdk:"name"`
-	Version  int                         `tfsdk:"version"`
+	ID                 types.String                `tfsdk:"id"`
+	Services           []*portProtocolResourceData `tfsdk:"services"`
+	Name               string                      `tfsdk:"name"`
+	Version            int                         `tfsdk:"version"`
+	DeletionProtection types.Bool                  `tfsdk:"deletion_protection"`
+	ForceDelete        types.Bool                  `tfsdk:"force_delete"`
 }
 
 type portProtocolResourceData struct {
@@ -179,7 +190,12 @@ func ApiToService(service api.Service) Service {
 }
 
 func (r *serviceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Service
+	ctx = r.p.logContext(ctx, "dog_service")
+	if !r.p.writable(&resp.Diagnostics, "create dog_service") {
+		return
+	}
+
+	var state serviceResourceState
 
 	var plan serviceResourceData
 	diags := req.Plan.Get(ctx, &plan)
@@ -190,10 +206,9 @@ func (r *serviceResource) Create(ctx context.Context, req resource.CreateRequest
 	}
 
 	newService := ServiceToCreateRequest(plan)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	service, statusCode, err := r.p.dog.CreateService(newService, nil)
-	log.Printf("service: %+v\n", service)
-	tflog.Trace(ctx, fmt.Sprintf("service: %+v\n", service))
+	r.p.audit(&resp.Diagnostics, "dog_service", "create", service.ID, plan.Name, statusCode, newService)
+	logResponse(ctx, "service", service)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create service, got error: %s", err))
 	}
@@ -203,7 +218,7 @@ func (r *serviceResource) Create(ctx context.Context, req resource.CreateRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToService(service)
+	state = serviceResourceState{Service: ApiToService(service), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 
 	plan.ID = state.ID
 
@@ -217,7 +232,8 @@ func (r *serviceResource) Create(ctx context.Context, req resource.CreateRequest
 }
 
 func (r *serviceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Service
+	ctx = r.p.logContext(ctx, "dog_service")
+	var state serviceResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -228,8 +244,6 @@ func (r *serviceResource) Read(ctx context.Context, req resource.ReadRequest, re
 
 	serviceID := state.ID.ValueString()
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	service, statusCode, err := r.p.dog.GetService(serviceID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -240,13 +254,18 @@ func (r *serviceResource) Read(ctx context.Context, req resource.ReadRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToService(service)
+	state = serviceResourceState{Service: ApiToService(service), DeletionProtection: state.DeletionProtection, ForceDelete: state.ForceDelete}
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *serviceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Service
+	ctx = r.p.logContext(ctx, "dog_service")
+	if !r.p.writable(&resp.Diagnostics, "update dog_service") {
+		return
+	}
+
+	var state serviceResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -266,9 +285,9 @@ func (r *serviceResource) Update(ctx context.Context, req resource.UpdateRequest
 
 	newService := ServiceToUpdateRequest(plan)
 	service, statusCode, err := r.p.dog.UpdateService(serviceID, newService, nil)
-	log.Printf("service: %+v\n", service)
-	tflog.Trace(ctx, fmt.Sprintf("service: %+v\n", service))
-	state = ApiToService(service)
+	r.p.audit(&resp.Diagnostics, "dog_service", "update", serviceID, plan.Name, statusCode, newService)
+	logResponse(ctx, "service", service)
+	state = serviceResourceState{Service: ApiToService(service), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create service, got error: %s", err))
 	}
@@ -293,7 +312,12 @@ func (r *serviceResource) Update(ctx context.Context, req resource.UpdateRequest
 }
 
 func (r *serviceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Service
+	ctx = r.p.logContext(ctx, "dog_service")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_service") {
+		return
+	}
+
+	var state serviceResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -302,8 +326,16 @@ func (r *serviceResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_service", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	serviceID := state.ID.ValueString()
+	if !r.p.unreferenced(&resp.Diagnostics, "dog_service", "service", serviceID, state.Name.ValueString(), state.ForceDelete) {
+		return
+	}
 	service, statusCode, err := r.p.dog.DeleteService(serviceID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_service", "delete", serviceID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -313,7 +345,7 @@ func (r *serviceResource) Delete(ctx context.Context, req resource.DeleteRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("service deleted: %+v\n", service))
+	logResponse(ctx, "service deleted", service)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
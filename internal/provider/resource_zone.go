@@ -3,7 +3,6 @@ package dog
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
@@ -18,6 +17,14 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// zoneResourceState is a Zone with the deletion_protection and force_delete
+// that are only kept in the state of a dog_zone.
+type zoneResourceState struct {
+	Zone
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+	ForceDelete        types.Bool `tfsdk:"force_delete"`
+}
+
 type (
 	zoneResource struct {
 		p dogProvider
@@ -43,6 +50,8 @@ func (*zoneResource) Schema(ctx context.Context, req resource.SchemaRequest, res
 		MarkdownDescription: "Zone data source",
 
 		Attributes: map[string]schema.Attribute{
+			"deletion_protection": deletionProtectionAttribute("dog_zone"),
+			"force_delete":        forceDeleteAttribute("dog_zone"),
 			// This description is used by the documentation generator and the language server.
 			"ipv4_addresses": schema.ListAttribute{
 				MarkdownDescription: "List of Ipv4 Addresses",
@@ -91,17 +100,17 @@ func (r *zoneResource) Configure(ctx context.Context, req resource.ConfigureRequ
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	provider, ok := req.ProviderData.(*dogProvider)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *dogProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.p.dog = client
+	r.p = *provider
 }
 
 func (*zoneResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -109,10 +118,12 @@ func (*zoneResource) ImportState(ctx context.Context, req resource.ImportStateRe
 }
 
 type zoneResourceData struct {
-	ID            types.String `tfsdk:"id"`
-	IPv4Addresses []string     `tfsdk:"ipv4_addresses"`
-	IPv6Addresses []string     `tfsdk:"ipv6_addresses"`
-	Name          string       `tfsdk:"name"`
+	ID                 types.String `tfsdk:"id"`
+	IPv4Addresses      []string     `tfsdk:"ipv4_addresses"`
+	IPv6Addresses      []string     `tfsdk:"ipv6_addresses"`
+	Name               string       `tfsdk:"name"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	ForceDelete        types.Bool   `tfsdk:"force_delete"`
 }
 
 func ZoneToCreateRequest(plan zoneResourceData) api.ZoneCreateRequest {
@@ -168,7 +179,12 @@ func ApiToZone(zone api.Zone) Zone {
 }
 
 func (r *zoneResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var state Zone
+	ctx = r.p.logContext(ctx, "dog_zone")
+	if !r.p.writable(&resp.Diagnostics, "create dog_zone") {
+		return
+	}
+
+	var state zoneResourceState
 
 	var plan zoneResourceData
 	diags := req.Plan.Get(ctx, &plan)
@@ -179,10 +195,9 @@ func (r *zoneResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	newZone := ZoneToCreateRequest(plan)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	zone, statusCode, err := r.p.dog.CreateZone(newZone, nil)
-	log.Printf("zone: %+v\n", zone)
-	tflog.Trace(ctx, fmt.Sprintf("zone: %+v\n", zone))
+	r.p.audit(&resp.Diagnostics, "dog_zone", "create", zone.ID, plan.Name, statusCode, newZone)
+	logResponse(ctx, "zone", zone)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create zone, got error: %s", err))
 	}
@@ -192,7 +207,7 @@ func (r *zoneResource) Create(ctx context.Context, req resource.CreateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToZone(zone)
+	state = zoneResourceState{Zone: ApiToZone(zone), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 
 	plan.ID = state.ID
 
@@ -206,7 +221,8 @@ func (r *zoneResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *zoneResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var state Zone
+	ctx = r.p.logContext(ctx, "dog_zone")
+	var state zoneResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -217,8 +233,6 @@ func (r *zoneResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 	zoneID := state.ID.ValueString()
 
-	log.Printf("r.p: %+v\n", r.p)
-	log.Printf("r.p.dog: %+v\n", r.p.dog)
 	zone, statusCode, err := r.p.dog.GetZone(zoneID, nil)
 	if statusCode != 200 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
@@ -229,13 +243,18 @@ func (r *zoneResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	state = ApiToZone(zone)
+	state = zoneResourceState{Zone: ApiToZone(zone), DeletionProtection: state.DeletionProtection, ForceDelete: state.ForceDelete}
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *zoneResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state Zone
+	ctx = r.p.logContext(ctx, "dog_zone")
+	if !r.p.writable(&resp.Diagnostics, "update dog_zone") {
+		return
+	}
+
+	var state zoneResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -255,9 +274,9 @@ func (r *zoneResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	newZone := ZoneToUpdateRequest(plan)
 	zone, statusCode, err := r.p.dog.UpdateZone(zoneID, newZone, nil)
-	log.Printf("zone: %+v\n", zone)
-	tflog.Trace(ctx, fmt.Sprintf("zone: %+v\n", zone))
-	state = ApiToZone(zone)
+	r.p.audit(&resp.Diagnostics, "dog_zone", "update", zoneID, plan.Name, statusCode, newZone)
+	logResponse(ctx, "zone", zone)
+	state = zoneResourceState{Zone: ApiToZone(zone), DeletionProtection: plan.DeletionProtection, ForceDelete: plan.ForceDelete}
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create zone, got error: %s", err))
 	}
@@ -282,7 +301,12 @@ func (r *zoneResource) Update(ctx context.Context, req resource.UpdateRequest, r
 }
 
 func (r *zoneResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state Zone
+	ctx = r.p.logContext(ctx, "dog_zone")
+	if !r.p.writable(&resp.Diagnostics, "delete dog_zone") {
+		return
+	}
+
+	var state zoneResourceState
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -291,8 +315,16 @@ func (r *zoneResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	if protectedFromDeletion(&resp.Diagnostics, "dog_zone", state.Name.ValueString(), state.DeletionProtection) {
+		return
+	}
+
 	zoneID := state.ID.ValueString()
+	if !r.p.unreferenced(&resp.Diagnostics, "dog_zone", "zone", zoneID, state.Name.ValueString(), state.ForceDelete) {
+		return
+	}
 	zone, statusCode, err := r.p.dog.DeleteZone(zoneID, nil)
+	r.p.audit(&resp.Diagnostics, "dog_zone", "delete", zoneID, state.Name.ValueString(), statusCode, nil)
 	if statusCode != 204 {
 		resp.Diagnostics.AddError("Client Unsuccesful", fmt.Sprintf("Status Code: %d", statusCode))
 	}
@@ -302,7 +334,7 @@ func (r *zoneResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("zone deleted: %+v\n", zone))
+	logResponse(ctx, "zone deleted", zone)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -0,0 +1,130 @@
+package dog
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// rulesetResourceKeyedRules are the keyed_rules of a dog_ruleset: the same
+// rules as in rules, by name, so that inserting or removing a rule doesn't
+// show every rule after it as changed.
+type rulesetResourceKeyedRules struct {
+	Inbound  map[string]*rulesetResourceKeyedRule `tfsdk:"inbound"`
+	Outbound map[string]*rulesetResourceKeyedRule `tfsdk:"outbound"`
+}
+
+type rulesetResourceKeyedRule struct {
+	rulesetResourceRule
+	Priority types.Int64 `tfsdk:"priority"`
+}
+
+func keyedRulesAttribute() schema.SingleNestedAttribute {
+	keyedRuleAttributes := rulesetRuleAttributes()
+	keyedRuleAttributes["priority"] = schema.Int64Attribute{
+		MarkdownDescription: "Position of the rule: rules are sent to dog by ascending priority, then by name. " +
+			"Rules without a priority come after those with one, in lexical order of their names, " +
+			"not in the order they are written in the configuration.",
+		Optional: true,
+	}
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Rules by name, instead of the rules lists. Inserting or removing a rule only changes that rule in the plan.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"inbound": schema.MapNestedAttribute{
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: keyedRuleAttributes,
+				},
+			},
+			"outbound": schema.MapNestedAttribute{
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: keyedRuleAttributes,
+				},
+			},
+		},
+		Validators: []validator.Object{
+			objectvalidator.ConflictsWith(path.MatchRoot("rules")),
+		},
+	}
+}
+
+// orderedKeys returns the names of the rules in the order they are sent to
+// dog. Terraform maps don't keep the order their entries are written in, so
+// rules without a priority are ordered by name.
+func orderedKeys(rules map[string]*rulesetResourceKeyedRule) []string {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := rules[keys[i]].Priority, rules[keys[j]].Priority
+		if pi.IsNull() != pj.IsNull() {
+			return pj.IsNull()
+		}
+		if pi.ValueInt64() != pj.ValueInt64() {
+			return pi.ValueInt64() < pj.ValueInt64()
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func orderedRules(rules map[string]*rulesetResourceKeyedRule) []*rulesetResourceRule {
+	ordered := []*rulesetResourceRule{}
+	for _, key := range orderedKeys(rules) {
+		rule := rules[key].rulesetResourceRule
+		ordered = append(ordered, &rule)
+	}
+	return ordered
+}
+
+// rules returns the keyed rules as the rules lists sent to dog.
+func (k *rulesetResourceKeyedRules) rules() *rulesetResourceRules {
+	return &rulesetResourceRules{
+		Inbound:  orderedRules(k.Inbound),
+		Outbound: orderedRules(k.Outbound),
+	}
+}
+
+// keyRules gives the rules read from dog the names and priorities of the
+// keyed rules of the prior state or plan, matching them by position. Rules
+// beyond the known ones, added outside of Terraform, are named rule_<position>
+// so they show up in the plan.
+func keyRules(rules []*rulesetResourceRule, prior map[string]*rulesetResourceKeyedRule) map[string]*rulesetResourceKeyedRule {
+	keys := orderedKeys(prior)
+	keyed := map[string]*rulesetResourceKeyedRule{}
+	for i, rule := range rules {
+		keyedRule := &rulesetResourceKeyedRule{rulesetResourceRule: *rule, Priority: types.Int64Null()}
+		if i < len(keys) {
+			keyedRule.Priority = prior[keys[i]].Priority
+			keyed[keys[i]] = keyedRule
+			continue
+		}
+		key := fmt.Sprintf("rule_%d", i+1)
+		for n := 2; prior[key] != nil; n++ {
+			key = fmt.Sprintf("rule_%d_%d", i+1, n)
+		}
+		keyed[key] = keyedRule
+	}
+	return keyed
+}
+
+// useKeyedRules moves the rules read from dog to keyed_rules when the prior
+// state or plan uses them.
+func (s *rulesetResourceState) useKeyedRules(prior *rulesetResourceKeyedRules) {
+	if prior == nil || s.Rules == nil {
+		return
+	}
+	s.KeyedRules = &rulesetResourceKeyedRules{
+		Inbound:  keyRules(s.Rules.Inbound, prior.Inbound),
+		Outbound: keyRules(s.Rules.Outbound, prior.Outbound),
+	}
+	s.Rules = nil
+}
@@ -0,0 +1,151 @@
+package dog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	api "github.com/relaypro-open/dog_api_golang/api"
+)
+
+// minimumDogVersion is the oldest dog trainer the provider works with.
+const minimumDogVersion = "1.4"
+
+// serverProbeTimeout bounds the request made during Configure, so that an
+// unreachable endpoint fails the plan instead of hanging it.
+const serverProbeTimeout = 30 * time.Second
+
+// dogFeatures maps the features of dog that resources can check in
+// dogServer.features to the dog version that added them. Fields that need a
+// newer dog add a feature here.
+var dogFeatures = map[string]string{
+	"facts": "1.4",
+	"links": "1.4",
+}
+
+// dogServer is what the provider found out about the dog trainer during
+// Configure.
+type dogServer struct {
+	// version is nil when dog doesn't report it
+	version  *version.Version
+	features map[string]bool
+}
+
+// newDogServer returns the features of a dog version, or all of them when the
+// version is unknown, leaving it to dog to reject what it doesn't support.
+func newDogServer(v *version.Version) dogServer {
+	s := dogServer{version: v, features: map[string]bool{}}
+	for feature, minimum := range dogFeatures {
+		s.features[feature] = v == nil || v.GreaterThanOrEqual(version.Must(version.NewVersion(minimum)))
+	}
+	return s
+}
+
+// serverVersionResponse is the body of GET /version, when dog returns JSON
+// rather than the bare version.
+type serverVersionResponse struct {
+	Version string `json:"version"`
+}
+
+// versionString returns the detected version, or "" when it is unknown.
+func (s dogServer) versionString() string {
+	if s.version == nil {
+		return ""
+	}
+	return s.version.Original()
+}
+
+// probeServer asks dog for its version, which also checks that the endpoint
+// is reachable and accepts the API key, and fails when dog is older than
+// minimumDogVersion. When dog doesn't report its version, the API key is
+// checked by listing one zone.
+func probeServer(ctx context.Context, c *api.Client, diags *diag.Diagnostics) dogServer {
+	ctx, cancel := context.WithTimeout(ctx, serverProbeTimeout)
+	defer cancel()
+	resp, err := c.R().SetContext(ctx).Get("/version")
+	if err != nil {
+		diags.AddError(
+			"Unable to Reach Dog API",
+			fmt.Sprintf("The provider cannot reach the Dog API at %s: %s", c.BaseURL, err),
+		)
+		return dogServer{}
+	}
+	switch resp.StatusCode() {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		addInvalidKeyError(c, resp.StatusCode(), diags)
+		return dogServer{}
+	case http.StatusNotFound:
+		// dog doesn't report its version, check the API key with the
+		// smallest authenticated request instead
+		resp, err = c.R().SetContext(ctx).SetQueryParams(map[string]string{"page_no": "1", "limit": "1"}).Get("/zones")
+		if err != nil {
+			diags.AddError(
+				"Unable to Reach Dog API",
+				fmt.Sprintf("The provider cannot reach the Dog API at %s: %s", c.BaseURL, err),
+			)
+			return dogServer{}
+		}
+		switch resp.StatusCode() {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden:
+			addInvalidKeyError(c, resp.StatusCode(), diags)
+			return dogServer{}
+		default:
+			diags.AddError(
+				"Unexpected Dog API Response",
+				fmt.Sprintf("The provider cannot list the zones of the Dog API at %s, got status code %d.", c.BaseURL, resp.StatusCode()),
+			)
+			return dogServer{}
+		}
+		diags.AddWarning(
+			"Unknown Dog Version",
+			fmt.Sprintf("The Dog API at %s does not report its version, so the provider cannot check that it is at least %s.", c.BaseURL, minimumDogVersion),
+		)
+		return newDogServer(nil)
+	default:
+		diags.AddError(
+			"Unexpected Dog API Response",
+			fmt.Sprintf("The provider cannot read the version of the Dog API at %s, got status code %d.", c.BaseURL, resp.StatusCode()),
+		)
+		return dogServer{}
+	}
+
+	raw := strings.TrimSpace(string(resp.Body()))
+	var body serverVersionResponse
+	var bare string
+	if json.Unmarshal(resp.Body(), &body) == nil && body.Version != "" {
+		raw = body.Version
+	} else if json.Unmarshal(resp.Body(), &bare) == nil {
+		raw = bare
+	}
+	v, err := version.NewVersion(raw)
+	if err != nil {
+		diags.AddError(
+			"Unexpected Dog API Response",
+			fmt.Sprintf("The Dog API at %s returned a version the provider cannot parse: %q", c.BaseURL, raw),
+		)
+		return dogServer{}
+	}
+	if v.LessThan(version.Must(version.NewVersion(minimumDogVersion))) {
+		diags.AddError(
+			"Unsupported Dog Version",
+			fmt.Sprintf("The Dog API at %s is dog %s, the provider needs dog %s or later.", c.BaseURL, v.Original(), minimumDogVersion),
+		)
+		return dogServer{}
+	}
+	return newDogServer(v)
+}
+
+func addInvalidKeyError(c *api.Client, statusCode int, diags *diag.Diagnostics) {
+	diags.AddError(
+		"Invalid Dog API Key",
+		fmt.Sprintf("The Dog API at %s rejected the API key with status code %d. "+
+			"Check api_token, api_token_file, api_token_command or the selected profile.", c.BaseURL, statusCode),
+	)
+}
@@ -0,0 +1,90 @@
+//go:build acceptance || datasource || references
+// +build acceptance datasource references
+
+package dog_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDogReferences_Zone(t *testing.T) {
+	randomName := "tf_test_references_" + acctest.RandString(5)
+	dataSourceName := "data.dog_references." + randomName
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogReferencesConfig_zone(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "rulesets.#", "1"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "rulesets.0.id", "dog_ruleset."+randomName, "id"),
+					resource.TestCheckResourceAttr(dataSourceName, "rulesets.0.direction", "inbound"),
+					resource.TestCheckResourceAttr(dataSourceName, "rulesets.0.rule_index", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "rulesets.0.comment", "from the zone"),
+					resource.TestCheckResourceAttr(dataSourceName, "groups.#", "0"),
+					resource.TestCheckResourceAttr(dataSourceName, "hosts.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogReferencesConfig_zone(name string) string {
+	return fmt.Sprintf(`
+resource "dog_zone" %[1]q {
+  name = %[1]q
+  ipv4_addresses = ["1.1.1.1"]
+  ipv6_addresses = []
+}
+
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  rules = {
+    inbound = [
+      {
+        action = "DROP"
+        active = "true"
+        comment = ""
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      },
+      {
+        action = "ACCEPT"
+        active = "true"
+        comment = "from the zone"
+        environments = []
+        group = dog_zone.%[1]s.id
+        group_type = "ZONE"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    ]
+    outbound = []
+  }
+}
+
+data "dog_references" %[1]q {
+  type = "zone"
+  id   = dog_zone.%[1]s.id
+
+  depends_on = [dog_ruleset.%[1]s]
+}
+`, name)
+}
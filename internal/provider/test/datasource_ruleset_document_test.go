@@ -0,0 +1,100 @@
+//go:build acceptance || datasource || ruleset_document
+// +build acceptance datasource ruleset_document
+
+package dog_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDogRulesetDocument_Merge(t *testing.T) {
+	randomName := "tf_test_ruleset_document_" + acctest.RandString(5)
+	dataSourceName := "data.dog_ruleset_document.service"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogRulesetDocumentConfig(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.#", "4"),
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.0.comment", "https"),
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.1.comment", "ssh from the bastion"),
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.1.log", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.2.comment", "dns"),
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.3.action", "DROP"),
+					resource.TestCheckResourceAttr(dataSourceName, "inbound.3.group", "any"),
+					resource.TestCheckResourceAttr(dataSourceName, "outbound.#", "1"),
+					resource.TestCheckResourceAttr("dog_ruleset."+randomName, "rules.inbound.#", "4"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogRulesetDocumentConfig(name string) string {
+	return fmt.Sprintf(`
+data "dog_ruleset_document" "baseline" {
+  rule {
+    key       = "ssh"
+    direction = "inbound"
+    action    = "ACCEPT"
+    comment   = "ssh"
+    service   = "ssh-tcp-22"
+  }
+  rule {
+    key       = "dns"
+    direction = "inbound"
+    action    = "ACCEPT"
+    comment   = "dns"
+  }
+  rule {
+    key       = "drop_all"
+    direction = "inbound"
+    action    = "DROP"
+  }
+  rule {
+    key       = "all"
+    direction = "outbound"
+    action    = "ACCEPT"
+  }
+}
+
+data "dog_ruleset_document" "bastion" {
+  rule {
+    key       = "ssh"
+    direction = "inbound"
+    action    = "ACCEPT"
+    comment   = "ssh from the bastion"
+    log       = true
+    service   = "ssh-tcp-22"
+  }
+}
+
+data "dog_ruleset_document" "service" {
+  source_documents   = [data.dog_ruleset_document.baseline.json]
+  override_documents = [data.dog_ruleset_document.bastion.json]
+
+  rule {
+    key       = "https"
+    direction = "inbound"
+    position  = "head"
+    action    = "ACCEPT"
+    comment   = "https"
+  }
+}
+
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  rules = {
+    inbound  = data.dog_ruleset_document.service.inbound
+    outbound = data.dog_ruleset_document.service.outbound
+  }
+}
+`, name)
+}
@@ -0,0 +1,34 @@
+//go:build acceptance || datasource || server
+// +build acceptance datasource server
+
+package dog_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestProvider_DogServer(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogServerDataSourceConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dog_server.current", "id", os.Getenv("DOG_API_ENDPOINT")),
+					resource.TestCheckResourceAttr("data.dog_server.current", "features.facts", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogServerDataSourceConfig() string {
+	return `
+data "dog_server" "current" {
+}
+`
+}
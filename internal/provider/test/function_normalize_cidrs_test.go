@@ -0,0 +1,36 @@
+//go:build acceptance || function || normalize_cidrs
+// +build acceptance function normalize_cidrs
+
+package dog_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestFunction_NormalizeCidrs(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "addresses" {
+  value = join(",", provider::dog::normalize_cidrs(["10.0.0.128/25", "10.0.0.1", "10.0.0.0/25", "2001:db8::1/128", "1.1.1.1/32"]))
+}
+`,
+				Check: resource.TestCheckOutput("addresses", "1.1.1.1,10.0.0.0/24,2001:db8::1"),
+			},
+			{
+				Config: `
+output "addresses" {
+  value = provider::dog::normalize_cidrs(["10.0.0.300"])
+}
+`,
+				ExpectError: regexp.MustCompile(`invalid address "10.0.0.300"`),
+			},
+		},
+	})
+}
@@ -0,0 +1,36 @@
+//go:build acceptance || function || parse_ports
+// +build acceptance function parse_ports
+
+package dog_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestFunction_ParsePorts(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "ports" {
+  value = join(",", provider::dog::parse_ports("80, 443,8000-8100,80"))
+}
+`,
+				Check: resource.TestCheckOutput("ports", "80,443,8000:8100"),
+			},
+			{
+				Config: `
+output "ports" {
+  value = provider::dog::parse_ports("8100-8000")
+}
+`,
+				ExpectError: regexp.MustCompile(`invalid range "8100-8000"`),
+			},
+		},
+	})
+}
@@ -0,0 +1,27 @@
+//go:build acceptance || function || terraform_name
+// +build acceptance function terraform_name
+
+package dog_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestFunction_TerraformName(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "name" {
+  value = provider::dog::terraform_name("1st zone.prod")
+}
+`,
+				Check: resource.TestCheckOutput("name", "_1st_zone_prod"),
+			},
+		},
+	})
+}
@@ -0,0 +1,92 @@
+//go:build acceptance || provider || audit
+// +build acceptance provider audit
+
+package dog_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider_DogAuditLog(t *testing.T) {
+	randomName := "audit_" + acctest.RandString(5)
+	auditLogPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogAuditLogConfig(auditLogPath, randomName),
+				Check:  testAccCheckDogAuditLog(auditLogPath, "create", randomName),
+			},
+		},
+	})
+}
+
+// testAccCheckDogAuditLog checks that the audit log records an operation on
+// the link, without its password.
+func testAccCheckDogAuditLog(auditLogPath string, operation string, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		f, err := os.Open(auditLogPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				return err
+			}
+			if record["resource_type"] != "dog_link" || record["operation"] != operation || record["name"] != name {
+				continue
+			}
+			connection, _ := record["request"].(map[string]any)["connection"].(map[string]any)
+			if connection["password"] != "REDACTED" {
+				return fmt.Errorf("link password not redacted: %v", connection["password"])
+			}
+			return nil
+		}
+		return fmt.Errorf("no %s record for dog_link %s in %s", operation, name, auditLogPath)
+	}
+}
+
+func testAccDogAuditLogConfig(auditLogPath string, randomName string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  audit_log_path = %[1]q
+}
+
+resource "dog_link" %[2]q {
+  address_handling = "union"
+  connection = {
+    api_port = 15672
+    host = "dog-broker.test.domain"
+    password = "apassword"
+    port = 5673
+    ssl_options = {
+      cacertfile = "certs/ca.crt"
+      certfile = "certs/server.crt"
+      fail_if_no_peer_cert = true
+      keyfile = "private/server.key"
+      server_name_indication = "disable"
+      verify = "verify_peer"
+    }
+    user = "dog_trainer"
+    virtual_host = "dog"
+  }
+  connection_type = "thumper"
+  direction = "bidirectional"
+  enabled = false
+  name = %[2]q
+}
+`, auditLogPath, randomName)
+}
@@ -0,0 +1,58 @@
+//go:build acceptance || provider || cache
+// +build acceptance provider cache
+
+package dog_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestProvider_DogCacheListResponses(t *testing.T) {
+	randomName := "cache_" + acctest.RandString(5)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogCacheListResponsesConfig(randomName, "1.1.1.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.dog_zone.first", "ipv4_addresses.0", "1.1.1.1"),
+					resource.TestCheckResourceAttr("data.dog_zone.second", "ipv4_addresses.0", "1.1.1.1"),
+					resource.TestCheckResourceAttrPair("data.dog_zone.first", "id", "dog_zone."+randomName, "id"),
+				),
+			},
+			{
+				Config: testAccDogCacheListResponsesConfig(randomName, "1.1.1.2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dog_zone."+randomName, "ipv4_addresses.0", "1.1.1.2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogCacheListResponsesConfig(randomName string, address string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  cache_list_responses = true
+}
+
+resource "dog_zone" %[1]q {
+  name = %[1]q
+  ipv4_addresses = [%[2]q]
+  ipv6_addresses = []
+}
+
+data "dog_zone" "first" {
+  name = dog_zone.%[1]s.name
+}
+
+data "dog_zone" "second" {
+  name = dog_zone.%[1]s.name
+}
+`, randomName, address)
+}
@@ -0,0 +1,179 @@
+//go:build acceptance || provider || policy
+// +build acceptance provider policy
+
+package dog_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestProvider_DogPolicy(t *testing.T) {
+	randomName := "policy_" + acctest.RandString(5)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDogPolicyConfig(randomName, ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`policy "comments": the rule must have a comment`),
+			},
+			{
+				Config:      testAccDogPolicyConfig(randomName, "from anywhere"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`policy "no-accept-from-any": rules matching it are not allowed`),
+			},
+		},
+	})
+}
+
+func testAccDogPolicyConfig(randomName string, comment string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  policy {
+    name            = "comments"
+    require_comment = true
+  }
+  policy {
+    name         = "no-accept-from-any"
+    direction    = "inbound"
+    action       = "ACCEPT"
+    group        = "any"
+    environments = ["prod"]
+    deny         = true
+  }
+}
+
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  rules = {
+    inbound = [
+      {
+        action = "ACCEPT"
+        active = "true"
+        comment = %[2]q
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    ]
+    outbound = []
+  }
+}
+`, randomName, comment)
+}
+
+func TestProvider_DogPolicyRequireLog(t *testing.T) {
+	randomName := "policy_" + acctest.RandString(5)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDogPolicyRequireLogConfig(randomName, false),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`policy "log-drops": the rule must have log = true`),
+			},
+			{
+				Config:             testAccDogPolicyRequireLogConfig(randomName, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestProvider_DogPolicyKeyedRules(t *testing.T) {
+	randomName := "policy_" + acctest.RandString(5)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDogPolicyKeyedRulesConfig(randomName),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`policy "comments": the rule must have a comment`),
+			},
+		},
+	})
+}
+
+func testAccDogPolicyRequireLogConfig(randomName string, log bool) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  policy {
+    name        = "log-drops"
+    action      = "DROP"
+    require_log = true
+  }
+}
+
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  rules = {
+    inbound = [
+      {
+        action = "DROP"
+        active = "true"
+        comment = "drop everything else"
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "%[2]t"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    ]
+    outbound = []
+  }
+}
+`, randomName, log)
+}
+
+func testAccDogPolicyKeyedRulesConfig(randomName string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  policy {
+    name            = "comments"
+    require_comment = true
+  }
+}
+
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  keyed_rules = {
+    inbound = {
+      drop_all = {
+        action = "DROP"
+        active = "true"
+        comment = ""
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    }
+    outbound = {}
+  }
+}
+`, randomName)
+}
@@ -0,0 +1,57 @@
+//go:build acceptance || provider || profile
+// +build acceptance provider profile
+
+package dog_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestProvider_DogProfile(t *testing.T) {
+	randomName := "profile_" + acctest.RandString(5)
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	config := fmt.Sprintf(`profiles:
+  acceptance:
+    api_endpoint: %q
+    api_token: %q
+`, os.Getenv("DOG_API_ENDPOINT"), os.Getenv("DOG_API_TOKEN"))
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOG_CONFIG_FILE", configFile)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogProfileConfig("acceptance", randomName),
+				Check:  resource.TestCheckResourceAttr("dog_zone."+randomName, "name", randomName),
+			},
+			{
+				Config:      testAccDogProfileConfig("missing", randomName),
+				ExpectError: regexp.MustCompile(`has no profile "missing"`),
+			},
+		},
+	})
+}
+
+func testAccDogProfileConfig(profile string, randomName string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  profile = %[1]q
+}
+
+resource "dog_zone" %[2]q {
+  name = %[2]q
+  ipv4_addresses = ["1.1.1.1"]
+  ipv6_addresses = []
+}
+`, profile, randomName)
+}
@@ -0,0 +1,41 @@
+//go:build acceptance || provider || read_only
+// +build acceptance provider read_only
+
+package dog_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestProvider_DogReadOnly(t *testing.T) {
+	randomName := "read_only_" + acctest.RandString(5)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDogReadOnlyConfig(randomName),
+				ExpectError: regexp.MustCompile(`Refusing to create dog_zone`),
+			},
+		},
+	})
+}
+
+func testAccDogReadOnlyConfig(randomName string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  read_only = true
+}
+
+resource "dog_zone" %[1]q {
+  name = %[1]q
+  ipv4_addresses = ["1.1.1.1"]
+  ipv6_addresses = []
+}
+`, randomName)
+}
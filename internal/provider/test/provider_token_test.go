@@ -0,0 +1,55 @@
+//go:build acceptance || provider || token
+// +build acceptance provider token
+
+package dog_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestProvider_DogApiTokenFile(t *testing.T) {
+	randomName := "token_" + acctest.RandString(5)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte(os.Getenv("DOG_API_TOKEN")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogApiTokenConfig(fmt.Sprintf("api_token_file = %q", tokenFile), randomName),
+				Check:  resource.TestCheckResourceAttr("dog_zone."+randomName, "name", randomName),
+			},
+			{
+				Config: testAccDogApiTokenConfig(fmt.Sprintf("api_token_command = %q", "cat "+tokenFile), randomName),
+				Check:  resource.TestCheckResourceAttr("dog_zone."+randomName, "name", randomName),
+			},
+			{
+				Config:      testAccDogApiTokenConfig(`api_token_command = "sleep 5"`+"\n"+`  api_token_command_timeout = "1s"`, randomName),
+				ExpectError: regexp.MustCompile(`did not finish within 1s`),
+			},
+		},
+	})
+}
+
+func testAccDogApiTokenConfig(tokenSource string, randomName string) string {
+	return fmt.Sprintf(`
+provider "dog" {
+  %[1]s
+}
+
+resource "dog_zone" %[2]q {
+  name = %[2]q
+  ipv4_addresses = ["1.1.1.1"]
+  ipv6_addresses = []
+}
+`, tokenSource, randomName)
+}
@@ -26,7 +26,7 @@ func TestAccDogLink_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "name", randomName),
 					resource.TestCheckResourceAttr(resourceName, "address_handling", "union"),
 					resource.TestCheckResourceAttr(resourceName, "dog_connection.port", "5673"),
-					resource.TestCheckResourceAttr(resourceName, "dog_connection.%", "7"),
+					resource.TestCheckResourceAttr(resourceName, "dog_connection.%", "9"),
 					resource.TestCheckResourceAttr(resourceName, "dog_connection.ssl_options.%", "6"),
 				),
 			},
@@ -39,6 +39,64 @@ func TestAccDogLink_Basic(t *testing.T) {
 	})
 }
 
+func TestAccDogLink_WriteOnlyPassword(t *testing.T) {
+	name := "dog_link"
+	randomName := "d9wo"
+	resourceName := name + "." + randomName
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogLinkConfig_writeOnlyPassword(name, randomName, "apassword", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(resourceName, "id"),
+					resource.TestCheckNoResourceAttr(resourceName, "dog_connection.password"),
+					resource.TestCheckNoResourceAttr(resourceName, "dog_connection.password_wo"),
+					resource.TestCheckResourceAttr(resourceName, "dog_connection.password_wo_version", "1"),
+				),
+			},
+			{
+				Config: testAccDogLinkConfig_writeOnlyPassword(name, randomName, "anotherpassword", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr(resourceName, "dog_connection.password"),
+					resource.TestCheckResourceAttr(resourceName, "dog_connection.password_wo_version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogLinkConfig_writeOnlyPassword(resourceName, name string, password string, version int) string {
+	return fmt.Sprintf(`
+resource %[1]q %[2]q {
+  address_handling = "union"
+  dog_connection = {
+    api_port = 15672
+    host = "dog-broker.test.domain"
+    password_wo = %[3]q
+    password_wo_version = %[4]d
+    port = 5673
+    ssl_options = {
+        cacertfile = "certs/ca.crt"
+        certfile = "certs/server.crt"
+        fail_if_no_peer_cert = true
+        keyfile = "private/server.key"
+        server_name_indication = "disable"
+        verify = "verify_peer"
+      }
+    user = "dog_trainer"
+    virtual_host = "dog"
+  }
+  connection_type = "thumper"
+  direction = "bidirectional"
+  enabled = false
+  name = %[2]q
+}
+`, resourceName, name, password, version)
+}
+
 func testAccDogLinkConfig_basic(resourceName, name string) string {
 	return fmt.Sprintf(`
 resource %[1]q %[2]q {
@@ -205,3 +205,176 @@ resource %[1]q %[2]q {
 }
 `, resourceName, name)
 }
+
+func TestAccDogRuleset_Keyed(t *testing.T) {
+	resourceType := "dog_ruleset"
+	randomName := "tf_test_ruleset_" + acctest.RandString(5)
+	resourceName := resourceType + "." + randomName
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogRulesetConfig_keyed(resourceType, randomName, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(resourceName, "id"),
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.inbound.%", "2"),
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.inbound.drop_all.action", "DROP"),
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.outbound.%", "1"),
+					resource.TestCheckNoResourceAttr(resourceName, "rules"),
+				),
+			},
+			{
+				Config: testAccDogRulesetConfig_keyed(resourceType, randomName, `
+      https = {
+        priority = 5
+        action = "ACCEPT"
+        active = "true"
+        comment = "inserted first"
+        environments = []
+        group = "dog_test"
+        group_type = "ROLE"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "https-tcp-443"
+        states = []
+        type = "BASIC"
+      }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.inbound.%", "3"),
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.inbound.https.comment", "inserted first"),
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.inbound.ssh.comment", "test_zone"),
+					resource.TestCheckResourceAttr(resourceName, "keyed_rules.inbound.drop_all.action", "DROP"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogRulesetConfig_keyed(resourceName, name string, extraInbound string) string {
+	return fmt.Sprintf(`
+resource %[1]q %[2]q {
+  name = %[2]q
+  keyed_rules = {
+    inbound = {%[3]s
+      ssh = {
+        priority = 10
+        action = "ACCEPT"
+        active = "true"
+        comment = "test_zone"
+        environments = []
+        group = "dog_test"
+        group_type = "ROLE"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "ssh-tcp-22"
+        states = []
+        type = "BASIC"
+      }
+      drop_all = {
+        priority = 1000
+        action = "DROP"
+        active = "true"
+        comment = ""
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    }
+    outbound = {
+      all = {
+        action = "ACCEPT"
+        active = "true"
+        comment = ""
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    }
+  }
+}
+`, resourceName, name, extraInbound)
+}
+
+// TestAccDogRuleset_KeyedLexicalOrder checks that keyed rules without a
+// priority are sent to dog in lexical order of their names, not in the order
+// they are declared.
+func TestAccDogRuleset_KeyedLexicalOrder(t *testing.T) {
+	randomName := "tf_test_ruleset_" + acctest.RandString(5)
+	dataSourceName := "data.dog_ruleset." + randomName
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogRulesetConfig_keyed_lexical_order(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "rules.inbound.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "rules.inbound.0.comment", "declared second"),
+					resource.TestCheckResourceAttr(dataSourceName, "rules.inbound.1.comment", "declared first"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogRulesetConfig_keyed_lexical_order(name string) string {
+	return fmt.Sprintf(`
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  keyed_rules = {
+    inbound = {
+      zz_ssh = {
+        action = "ACCEPT"
+        active = "true"
+        comment = "declared first"
+        environments = []
+        group = "dog_test"
+        group_type = "ROLE"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "ssh-tcp-22"
+        states = []
+        type = "BASIC"
+      }
+      aa_drop = {
+        action = "DROP"
+        active = "true"
+        comment = "declared second"
+        environments = []
+        group = "any"
+        group_type = "ANY"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    }
+    outbound = {}
+  }
+}
+
+data "dog_ruleset" %[1]q {
+  name = dog_ruleset.%[1]s.name
+}
+`, name)
+}
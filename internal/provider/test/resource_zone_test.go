@@ -5,6 +5,7 @@ package dog_test
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
@@ -47,3 +48,115 @@ resource %[1]q %[2]q {
 }
 `, resourceName, name)
 }
+
+func TestAccDogZone_DeletionProtection(t *testing.T) {
+	name := "dog_zone"
+	randomName := "tf_test_zone_" + acctest.RandString(5)
+	resourceName := name + "." + randomName
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogZoneConfig_deletionProtection(name, randomName, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "deletion_protection", "true"),
+				),
+			},
+			{
+				Config:      testAccDogZoneConfig_deletionProtection(name, randomName, true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`Deletion Protection Enabled`),
+			},
+			{
+				// lifting the protection lets the test case destroy the zone
+				Config: testAccDogZoneConfig_deletionProtection(name, randomName, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "deletion_protection", "false"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogZoneConfig_deletionProtection(resourceName, name string, deletionProtection bool) string {
+	return fmt.Sprintf(`
+resource %[1]q %[2]q {
+  name = %[2]q
+  ipv4_addresses = ["1.1.1.1"]
+  ipv6_addresses = []
+  deletion_protection = %[3]t
+}
+`, resourceName, name, deletionProtection)
+}
+
+func TestAccDogZone_InUse(t *testing.T) {
+	randomName := "tf_test_zone_" + acctest.RandString(5)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDogZoneConfig_inUse(randomName, true, false),
+			},
+			{
+				// the ruleset refers to the zone through the data source, so
+				// nothing orders its changes before the zone's deletion
+				Config:      testAccDogZoneConfig_inUse(randomName, false, false),
+				ExpectError: regexp.MustCompile(`Zone Still In Use`),
+			},
+			{
+				Config: testAccDogZoneConfig_inUse(randomName, true, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("dog_zone."+randomName, "force_delete", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDogZoneConfig_inUse(name string, withZone bool, forceDelete bool) string {
+	zone, dependsOn := "", ""
+	if withZone {
+		dependsOn = fmt.Sprintf("depends_on = [dog_zone.%s]", name)
+		zone = fmt.Sprintf(`
+resource "dog_zone" %[1]q {
+  name = %[1]q
+  ipv4_addresses = ["1.1.1.1"]
+  ipv6_addresses = []
+  force_delete = %[2]t
+}
+`, name, forceDelete)
+	}
+	return zone + fmt.Sprintf(`
+data "dog_zone" %[1]q {
+  name = %[1]q
+  %[2]s
+}
+
+resource "dog_ruleset" %[1]q {
+  name = %[1]q
+  rules = {
+    inbound = [
+      {
+        action = "ACCEPT"
+        active = "true"
+        comment = ""
+        environments = []
+        group = data.dog_zone.%[1]s.id
+        group_type = "ZONE"
+        interface = ""
+        log = "false"
+        log_prefix = ""
+        service = "any"
+        states = []
+        type = "BASIC"
+      }
+    ]
+    outbound = []
+  }
+}
+`, name, dependsOn)
+}
@@ -0,0 +1,98 @@
+package dog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// defaultTokenCommandTimeout is how long api_token_command may run when no
+// timeout is given.
+const defaultTokenCommandTimeout = 30 * time.Second
+
+// tokenSource is where the API token is read from. At most one of token,
+// file and command is used, in that order. dog-import/token.go has a copy of
+// this file that must be kept in sync with it.
+type tokenSource struct {
+	token   string
+	file    string
+	command string
+	timeout time.Duration
+}
+
+// envTokenSource reads the token source from DOG_API_TOKEN,
+// DOG_API_TOKEN_FILE, DOG_API_TOKEN_COMMAND and
+// DOG_API_TOKEN_COMMAND_TIMEOUT.
+func envTokenSource() (tokenSource, error) {
+	s := tokenSource{
+		token:   os.Getenv("DOG_API_TOKEN"),
+		file:    os.Getenv("DOG_API_TOKEN_FILE"),
+		command: os.Getenv("DOG_API_TOKEN_COMMAND"),
+		timeout: defaultTokenCommandTimeout,
+	}
+	if v := os.Getenv("DOG_API_TOKEN_COMMAND_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return s, fmt.Errorf("DOG_API_TOKEN_COMMAND_TIMEOUT must be a duration such as 30s, got: %q", v)
+		}
+		s.timeout = timeout
+	}
+	return s, nil
+}
+
+// resolve returns the token. The file is read and the command run every
+// time, so a rotated token is picked up by the next Terraform command.
+func (s tokenSource) resolve(ctx context.Context) (string, error) {
+	switch {
+	case s.token != "":
+		return s.token, nil
+	case s.file != "":
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return "", err
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("%s is empty", s.file)
+		}
+		return token, nil
+	case s.command != "":
+		return runTokenCommand(ctx, s.command, s.timeout)
+	default:
+		return "", nil
+	}
+}
+
+// runTokenCommand runs command with the system shell and returns its
+// standard output. Only standard error is included in errors, since the
+// output may hold part of the token.
+func runTokenCommand(ctx context.Context, command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%q did not finish within %s", command, timeout)
+		}
+		return "", fmt.Errorf("%q failed: %s: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("%q printed no token", command)
+	}
+	return token, nil
+}